@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/andreas-bauer/rejoinderoo/rejoinder"
+)
+
+// genFlags are the flags that select the input and configure the generated rejoinder.
+// The root command and the watch subcommand share them, see register.
+type genFlags struct {
+	inFile       string
+	columns      string
+	template     string
+	templateFile string
+	output       string
+	pdf          bool
+	sheet        string
+	markup       bool
+	escaping     string
+	idScheme     string
+	toc          bool
+	palette      string
+	order        string
+	where        string
+	roles        string
+}
+
+// register defines the flags on the flag set. The usage of -output and -pdf depends on the command.
+func (f *genFlags) register(fs *flag.FlagSet, outputUsage, pdfUsage string) {
+	fs.StringVar(&f.inFile, "i", "", "file path to input file (CSV or Excel)")
+	fs.StringVar(&f.columns, "columns", "", "comma-separated list of columns to include, e.g., \"ID,Comment,Response,Action\"")
+	fs.StringVar(&f.template, "template", "", fmt.Sprintf("output template, one of %v or their aliases (default %s)", rejoinder.TemplateNames(), rejoinder.TemplateNames()[0]))
+	fs.StringVar(&f.templateFile, "template-file", "", fmt.Sprintf("custom template that replaces the built-in one of -template, supported for %v, see '%s template export -h'", rejoinder.TemplatesWith(rejoinder.OptionCustom), os.Args[0]))
+	fs.StringVar(&f.output, "output", "", outputUsage)
+	fs.BoolVar(&f.pdf, "pdf", false, pdfUsage)
+	fs.StringVar(&f.sheet, "sheet", "", "name or 1-based index of the Excel sheet to read (default first sheet)")
+	fs.BoolVar(&f.markup, "markup", false, "convert Markdown-like markup in cells, e.g., lists, **bold**, and [links](url), for LaTeX and Typst")
+	fs.StringVar(&f.escaping, "escaping", "", fmt.Sprintf("escaping of LaTeX and Typst special characters, one of %v; math passes $...$ and {{raw}}...{{/raw}} through (default strict)", rejoinder.EscapingNames()))
+	fs.StringVar(&f.idScheme, "id-scheme", "", fmt.Sprintf("scheme of the comment IDs, one of %v or a regular expression with the named groups reviewer and comment, e.g., \"^(?P<reviewer>R\\d+)\\.(?P<comment>\\d+)$\" (default splits at the first . - : or space)", rejoinder.IDSchemePresets()))
+	fs.BoolVar(&f.toc, "toc", false, "add a table of contents of the reviewer sections to LaTeX and Typst")
+	fs.StringVar(&f.palette, "palette", "", fmt.Sprintf("color palette for the reviewers, one of %v (default %s)", rejoinder.PaletteNames(), rejoinder.PaletteNames()[0]))
+	fs.StringVar(&f.order, "order", "", fmt.Sprintf("order of the responses, one of %v, where column=<header> sorts by a column and reviewers=<list> lists the reviewers first, e.g., \"reviewers=Editor, R2, R1\" (default sheet order)", rejoinder.OrderModes()))
+	fs.StringVar(&f.where, "where", "", "keep only the rows that match a filter, e.g., \"Status=done\", \"Status!=done\", or \"Responsible in (Andreas, Maria)\"; join conditions with and")
+	fs.StringVar(&f.roles, "roles", "", fmt.Sprintf("comma-separated column roles of the form <column>=<role> with the roles %v, e.g., \"No=id,Remark=comment,Status=hidden\" (default ID in the first and comment in the second column)", rejoinder.RoleNames()))
+}
@@ -1,71 +1,512 @@
 package main
 
 import (
+	"cmp"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 
-	"github.com/andreas-bauer/rejoinderoo/internal/reader"
-	"github.com/andreas-bauer/rejoinderoo/internal/templates"
+	"github.com/andreas-bauer/rejoinderoo/internal/compile"
+	"github.com/andreas-bauer/rejoinderoo/internal/config"
 	"github.com/andreas-bauer/rejoinderoo/internal/tui"
+	"github.com/andreas-bauer/rejoinderoo/rejoinder"
 )
 
+// Exit codes of the CLI.
+const (
+	exitOK    = 0 // the rejoinder was generated
+	exitError = 1 // reading, rendering, or writing failed
+	exitUsage = 2 // invalid flags or arguments
+)
+
+// minSelectedColumns is the number of columns a rejoinder needs at least (ID, comment, response).
+const minSelectedColumns = 3
+
+// stdoutFilename is the output file name that writes the rejoinder to stdout.
+const stdoutFilename = "-"
+
 func main() {
-	inFileFlag := flag.String("i", "", "file path to input file (CSV or Excel)")
-	flag.Parse()
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the CLI with the command line arguments, without the program name,
+// and returns the exit code. The rejoinder is written to stdout for -output -,
+// while errors, warnings, and the usage go to stderr.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "watch":
+			return runWatch(args[1:], stderr)
+		case "template":
+			return runTemplate(args[1:], stdout, stderr)
+		}
+	}
+
+	fs := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var gen genFlags
+	gen.register(fs, "file path of the generated rejoinder, use - for stdout", "compile the generated rejoinder to PDF with latexmk, pdflatex, lualatex, or typst")
+	roundsFlag := fs.String("rounds", "", "comma-separated Excel sheets (names or 1-based indices) to combine as review rounds in chronological order, or \"all\"")
+	appendixFlag := fs.Bool("appendix", false, "with -rounds, move all but the last review round into an appendix")
+	fs.Usage = func() { usage(fs) }
+	if err := fs.Parse(args); err != nil {
+		return parseExitCode(err)
+	}
+
+	// Any of the generation flags switches to non-interactive mode
+	interactive := gen.columns == "" && gen.template == "" && gen.output == ""
 
-	inFile := *inFileFlag
-	if *inFileFlag == "" {
+	inFile := gen.inFile
+	if inFile == "" {
+		if !interactive {
+			return usageError(stderr, "flag -i is required in non-interactive mode")
+		}
 		inFile = tui.RunFilePicker()
 	}
 
-	reader, err := reader.NewReader(inFile)
+	cfg, _, err := config.Discover(inFile)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		fmt.Fprintln(stderr, "Error reading config file:", err)
+		return exitError
 	}
 
-	file, errR1 := os.Open(inFile)
-	td, errR2 := reader.Read(file)
-	if errR1 != nil || errR2 != nil {
-		fmt.Println("Error reading file:")
-		fmt.Println("Unable to proceed.")
-		os.Exit(1)
+	if *appendixFlag && *roundsFlag == "" {
+		return usageError(stderr, "-appendix requires -rounds")
+	}
+	if *roundsFlag != "" && gen.sheet != "" {
+		return usageError(stderr, "-sheet cannot be combined with -rounds")
+	}
+
+	sheet := cmp.Or(gen.sheet, cfg.Sheet)
+	if sheet == "" && *roundsFlag == "" && interactive {
+		sheet, err = pickSheet(inFile)
+		if err != nil {
+			fmt.Fprintln(stderr, "Error reading file:", err)
+			return exitError
+		}
+	}
+
+	// With several review rounds, the columns are selected from the last round
+	// and the remaining rounds must provide the same columns.
+	doc, err := rejoinder.ReadFile(inFile, readOptions(sheet, *roundsFlag))
+	if err != nil {
+		fmt.Fprintln(stderr, "Error reading file:", err)
+		return exitError
+	}
+	headers := doc.Headers()
+
+	roles, err := rejoinder.ParseRoles(gen.roles)
+	if err != nil {
+		return usageError(stderr, err.Error())
+	}
+	if len(roles) == 0 {
+		roles = configRoles(cfg)
 	}
-	defer file.Close()
 
 	fd := &tui.FormData{
-		AvailableHeaders: td.Headers,
+		AvailableHeaders: headers,
+		SelectedHeaders:  existingHeaders(cfg.Columns, headers),
+		Template:         cmp.Or(gen.template, cfg.Template),
+		Palette:          cmp.Or(gen.palette, cfg.Palette),
+		Order:            cmp.Or(gen.order, cfg.Order),
+		Where:            cmp.Or(gen.where, cfg.Where),
+		Roles:            roles,
+		Filename:         gen.output,
+		PDF:              gen.pdf,
+	}
+
+	if interactive {
+		// preselect the template of the config file, even if given by an alias
+		if tmpl, err := rejoinder.LookupTemplate(fd.Template); err == nil {
+			fd.Template = tmpl.Name()
+		}
+		err = tui.RunForm(fd)
+		if err != nil {
+			fmt.Fprintln(stderr, "Error running TUI form:", err)
+			return exitError
+		}
+	} else {
+		err = applyFlags(fd, headers, gen.columns)
+		if err != nil {
+			return usageError(stderr, err.Error())
+		}
 	}
-	err = tui.RunForm(fd)
+
+	tmpl, err := rejoinder.LookupTemplate(fd.Template)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "Error running TUI form:", err)
-		os.Exit(1)
+		return usageError(stderr, err.Error())
+	}
+	custom, err := loadCustomTemplate(tmpl, cmp.Or(gen.templateFile, cfg.TemplateFile))
+	if err != nil {
+		fmt.Fprintln(stderr, "Error loading custom template:", err)
+		return exitError
 	}
 
-	td.Keep(fd.SelectedHeaders)
+	scheme, err := rejoinder.ParseIDScheme(cmp.Or(gen.idScheme, cfg.IDScheme))
+	if err != nil {
+		return usageError(stderr, err.Error())
+	}
 
-	tmpl := templates.NewTemplate(fd.Template)
+	if doc.Rounds() != nil && !tmpl.Supports(rejoinder.OptionRounds) {
+		return usageError(stderr, fmt.Sprintf("template %s cannot combine several review rounds", fd.Template))
+	}
+	err = doc.Select(rejoinder.Selection{
+		Columns:  fd.SelectedHeaders,
+		Roles:    fd.Roles,
+		Where:    fd.Where,
+		Order:    fd.Order,
+		IDScheme: scheme,
+	})
+	if err != nil {
+		return usageError(stderr, err.Error())
+	}
+	for _, round := range emptyRounds(doc) {
+		if round == "" {
+			fmt.Fprintf(stderr, "Warning: no rows match the filter %s\n", fd.Where)
+		} else {
+			fmt.Fprintf(stderr, "Warning: sheet %q: no rows match the filter %s\n", round, fd.Where)
+		}
+	}
 
 	if strings.TrimSpace(fd.Filename) == "" {
 		fd.Filename = "output"
 	}
-	fd.Filename = appendExtensionIfNotPresent(fd.Filename, tmpl.FileExtension())
+	if fd.Filename != stdoutFilename {
+		fd.Filename = appendExtensionIfNotPresent(fd.Filename, tmpl.Extension())
+	}
+
+	if fd.PDF {
+		if fd.Filename == stdoutFilename {
+			return usageError(stderr, "-pdf cannot be combined with writing to stdout")
+		}
+		if !compile.Supported(tmpl.Extension()) {
+			return usageError(stderr, fmt.Sprintf("template %s cannot be compiled to PDF", fd.Template))
+		}
+	}
+
+	escaping, err := rejoinder.ParseEscaping(cmp.Or(gen.escaping, cfg.EscapingFor(fd.Template)))
+	if err != nil {
+		return usageError(stderr, err.Error())
+	}
+
+	palette, err := rejoinder.ParsePalette(fd.Palette)
+	if err != nil {
+		return usageError(stderr, err.Error())
+	}
+	for _, name := range ignoredFlags(tmpl, fs) {
+		fmt.Fprintf(stderr, "Warning: template %s ignores -%s\n", tmpl.Name(), name)
+	}
+
+	opts := rejoinder.Options{
+		Meta:             rejoinder.Metadata(cfg.Metadata()),
+		AppendixRounds:   *appendixFlag,
+		CellMarkup:       gen.markup || cfg.CellMarkup,
+		Escaping:         escaping,
+		UnicodeFallbacks: cfg.Fallbacks(),
+		IDScheme:         scheme,
+		Palette:          palette,
+		Colors:           reviewerColors(cfg),
+		TableOfContents:  gen.toc || cfg.TOC,
+		Template:         custom,
+	}
+
+	for _, msg := range tmpl.Warnings(doc, opts) {
+		fmt.Fprintln(stderr, "Warning:", msg)
+	}
+
+	// the output file is written only if rendering succeeds, while stdout is streamed
+	var out []byte
+	if fd.Filename == stdoutFilename {
+		err = tmpl.Render(context.Background(), stdout, doc, opts)
+	} else {
+		out, err = tmpl.RenderBytes(context.Background(), doc, opts)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "Error rendering template:", err)
+		return exitError
+	}
+
+	if fd.Filename != stdoutFilename {
+		if err := os.WriteFile(fd.Filename, out, 0644); err != nil {
+			fmt.Fprintln(stderr, "Error saving output file:", err)
+			return exitError
+		}
+	}
+
+	if fd.PDF {
+		fd.PDFFilename, err = compilePDF(fd.Filename, out, renderOrder(doc, opts))
+		if err != nil {
+			reportCompileError(stderr, err)
+			return exitError
+		}
+	}
+
+	if interactive {
+		tui.PrintSummary(fd)
+	}
+	return exitOK
+}
+
+func usage(fs *flag.FlagSet) {
+	fmt.Fprintf(fs.Output(), `Usage: %s [flags]
+       %s watch -i <file> [flags]
+
+Without -columns, -template, or -output an interactive form asks for the
+columns, the template, and the output file. Providing any of these flags
+skips the form; omitted columns default to all columns and an omitted
+template defaults to LaTeX.
+
+With -rounds, several sheets of an Excel workbook, e.g., one per review
+round, are combined into one document with a section per round.
+
+A rejoinderoo.yaml file next to the input file provides the paper metadata
+(title, manuscript ID, authors, ...) and default columns and template.
+
+The watch subcommand regenerates the rejoinder every time the input file
+is saved, see '%s watch -h'.
+
+With -template-file, a custom template, e.g., in the house style of your
+institute, replaces the built-in one. The template subcommand exports the
+built-in templates as a starting point, see '%s template export -h'.
+
+Templates:
+%s
+Flags:
+`, os.Args[0], os.Args[0], os.Args[0], os.Args[0], templateUsage())
+	fs.PrintDefaults()
+}
+
+// templateUsage lists the registered templates with their aliases, descriptions, and options.
+func templateUsage() string {
+	var b strings.Builder
+	for _, t := range rejoinder.Templates() {
+		name := t.Name()
+		if aliases := t.Aliases(); len(aliases) > 0 {
+			name += " (" + strings.Join(aliases, ", ") + ")"
+		}
+		fmt.Fprintf(&b, "  %-18s %s, %s\n", name, t.Description(), t.Extension())
+		if options := t.Options(); len(options) > 0 {
+			opts := make([]string, len(options))
+			for i, o := range options {
+				opts[i] = "-" + o
+			}
+			fmt.Fprintf(&b, "  %-18s supports %s\n", "", strings.Join(opts, " "))
+		}
+	}
+	return b.String()
+}
+
+// ignoredFlags returns the names of the flags that are set but ignored by the template, e.g., -toc for Markdown.
+func ignoredFlags(tmpl rejoinder.Template, fs *flag.FlagSet) []string {
+	var res []string
+	fs.Visit(func(f *flag.Flag) {
+		if slices.Contains(rejoinder.OptionNames(), f.Name) && !tmpl.Supports(f.Name) {
+			res = append(res, f.Name)
+		}
+	})
+	return res
+}
+
+// usageError prints the error of invalid flags or arguments with a hint to the usage
+// and returns the exit code for usage errors.
+func usageError(stderr io.Writer, msg string) int {
+	fmt.Fprintln(stderr, "Error:", msg)
+	fmt.Fprintf(stderr, "Run '%s -h' for usage.\n", os.Args[0])
+	return exitUsage
+}
+
+// parseExitCode returns the exit code for an error of parsing the flags. The flag set
+// has already printed the error and the usage; asking for the usage with -h is no error.
+func parseExitCode(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	return exitUsage
+}
+
+// loadCustomTemplate reads the custom template at path and validates it as source of the template.
+// An empty path selects the built-in template.
+func loadCustomTemplate(tmpl rejoinder.Template, path string) (*rejoinder.CustomTemplate, error) {
+	if path == "" {
+		return nil, nil
+	}
+	custom, err := rejoinder.LoadCustomTemplate(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := tmpl.ValidateCustom(context.Background(), custom); err != nil {
+		return nil, err
+	}
+	return custom, nil
+}
+
+// readOptions returns the options to read the given sheet or the given comma-separated
+// review rounds, or all sheets as review rounds for "all".
+func readOptions(sheet, rounds string) rejoinder.ReadOptions {
+	if rounds == "" {
+		return rejoinder.ReadOptions{Sheet: sheet}
+	}
+	if strings.EqualFold(strings.TrimSpace(rounds), "all") {
+		return rejoinder.ReadOptions{AllRounds: true}
+	}
+	return rejoinder.ReadOptions{Rounds: parseColumns(rounds)}
+}
+
+// emptyRounds returns the review rounds without comments, or a single empty name
+// if a document without rounds has no comments, e.g., because no rows match the filter.
+func emptyRounds(doc *rejoinder.Document) []string {
+	counts := make(map[string]int)
+	for _, c := range doc.Comments() {
+		counts[c.Round]++
+	}
+	rounds := doc.Rounds()
+	if rounds == nil {
+		rounds = []string{""}
+	}
+	var res []string
+	for _, r := range rounds {
+		if counts[r] == 0 {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// renderOrder returns the origins of the comments in the order they are rendered, see Document.RenderOrder,
+// e.g., to map the response boxes of the generated source back to the spreadsheet.
+func renderOrder(doc *rejoinder.Document, opts rejoinder.Options) []compile.Origin {
+	var res []compile.Origin
+	for _, c := range doc.RenderOrder(opts) {
+		res = append(res, compile.Origin{Row: c.Row, ID: c.ID})
+	}
+	return res
+}
+
+// configRoles returns the column roles of the config file.
+func configRoles(cfg *config.Config) map[string]rejoinder.Role {
+	var res map[string]rejoinder.Role
+	for h, r := range cfg.ColumnRoles() {
+		if res == nil {
+			res = make(map[string]rejoinder.Role)
+		}
+		res[h] = rejoinder.Role(r)
+	}
+	return res
+}
+
+// reviewerColors returns the reviewer colors of the config file.
+func reviewerColors(cfg *config.Config) map[string]rejoinder.Color {
+	var res map[string]rejoinder.Color
+	for r, c := range cfg.ReviewerColors() {
+		if res == nil {
+			res = make(map[string]rejoinder.Color)
+		}
+		res[r] = rejoinder.Color(c)
+	}
+	return res
+}
 
-	out, err := tmpl.Render(*td)
+// pickSheet lets the user select a sheet, if the input file contains more than one.
+func pickSheet(inFile string) (string, error) {
+	file, err := os.Open(inFile)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "Error rendering template:", err)
-		os.Exit(1)
+		return "", err
+	}
+	defer file.Close()
+
+	sheets, err := rejoinder.Sheets(file, inFile)
+	if err != nil || len(sheets) < 2 {
+		return "", err
 	}
+	return tui.RunSheetPicker(sheets)
+}
 
-	err = os.WriteFile(fd.Filename, []byte(out), 0644)
+// compilePDF compiles the generated source next to srcPath with the first installed toolchain.
+// The origins are the comments in the order they are rendered, see renderOrder.
+func compilePDF(srcPath string, source []byte, origins []compile.Origin) (string, error) {
+	ext := filepath.Ext(srcPath)
+	c, err := compile.Find(ext)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "Error saving output file:", err)
-		os.Exit(1)
+		return "", err
+	}
+	return c.Compile(context.Background(), srcPath, source, compile.NewSourceMap(source, ext, origins))
+}
+
+// compileLogLines is the number of compiler log lines shown if compilation fails.
+const compileLogLines = 20
+
+func reportCompileError(stderr io.Writer, err error) {
+	var compileErr *compile.Error
+	if errors.As(err, &compileErr) {
+		fmt.Fprintf(stderr, "%s log (last %d lines):\n%s\n\n", compileErr.Compiler, compileLogLines, compileErr.LogTail(compileLogLines))
 	}
+	fmt.Fprintln(stderr, "Error compiling PDF:", err)
+}
 
-	tui.PrintSummary(fd)
+// applyFlags fills the form data from the command line flags and validates them
+// against the available headers and templates.
+func applyFlags(fd *tui.FormData, headers []string, columns string) error {
+	if selected := parseColumns(columns); len(selected) > 0 {
+		fd.SelectedHeaders = selected
+	}
+	if len(fd.SelectedHeaders) == 0 {
+		fd.SelectedHeaders = headers
+	}
+	// columns with a role are kept, even if they are not selected
+	for _, h := range headers {
+		if fd.Roles[h] != rejoinder.NoRole && !slices.Contains(fd.SelectedHeaders, h) {
+			fd.SelectedHeaders = append(slices.Clip(fd.SelectedHeaders), h)
+		}
+	}
+
+	var missing []string
+	for _, h := range fd.SelectedHeaders {
+		if !slices.Contains(headers, h) {
+			missing = append(missing, h)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("column(s) %q not found, available columns are %q", missing, headers)
+	}
+	if len(fd.SelectedHeaders) < minSelectedColumns {
+		return fmt.Errorf("at least %d columns need to be selected, got %d", minSelectedColumns, len(fd.SelectedHeaders))
+	}
+
+	tmpl, err := rejoinder.LookupTemplate(fd.Template)
+	if err != nil {
+		return err
+	}
+	fd.Template = tmpl.Name()
+	return nil
+}
+
+// existingHeaders returns the given headers that exist among the available ones,
+// e.g., to preselect the columns from a config file.
+func existingHeaders(headers, available []string) []string {
+	var res []string
+	for _, h := range headers {
+		if slices.Contains(available, h) {
+			res = append(res, h)
+		}
+	}
+	return res
+}
+
+// parseColumns splits a comma-separated list of column names.
+func parseColumns(columns string) []string {
+	var res []string
+	for c := range strings.SplitSeq(columns, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			res = append(res, c)
+		}
+	}
+	return res
 }
 
 func appendExtensionIfNotPresent(filename, ext string) string {
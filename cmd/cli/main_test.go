@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andreas-bauer/rejoinderoo/internal/tui"
+	"github.com/andreas-bauer/rejoinderoo/rejoinder"
+)
+
+func TestParseColumns(t *testing.T) {
+	tests := []struct {
+		columns  string
+		expected []string
+	}{
+		{"", nil},
+		{" , ,", nil},
+		{"ID", []string{"ID"}},
+		{"ID,Comment,Response", []string{"ID", "Comment", "Response"}},
+		{" ID , Comment ,, Response ", []string{"ID", "Comment", "Response"}},
+		{"Round 1,Round 2", []string{"Round 1", "Round 2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.columns, func(t *testing.T) {
+			if got := parseColumns(tt.columns); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("parseColumns(%q) = %q; want %q", tt.columns, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestApplyFlags(t *testing.T) {
+	headers := []string{"ID", "Comment", "Response", "Status"}
+	tests := []struct {
+		name         string
+		fd           tui.FormData
+		columns      string
+		wantHeaders  []string
+		wantTemplate string
+		wantErr      bool
+	}{
+		{"Defaults", tui.FormData{}, "", headers, "LaTeX", false},
+		{"Columns", tui.FormData{}, "ID, Comment, Response", []string{"ID", "Comment", "Response"}, "LaTeX", false},
+		{"Columns of the config file", tui.FormData{SelectedHeaders: []string{"ID", "Comment", "Response"}}, "", []string{"ID", "Comment", "Response"}, "LaTeX", false},
+		{"Columns override the config file", tui.FormData{SelectedHeaders: []string{"ID"}}, "ID,Comment,Status", []string{"ID", "Comment", "Status"}, "LaTeX", false},
+		{"Role columns are kept", tui.FormData{Roles: map[string]rejoinder.Role{"Status": rejoinder.HiddenRole}}, "ID,Comment,Response", headers, "LaTeX", false},
+		{"Template alias", tui.FormData{Template: "md"}, "", headers, "Markdown", false},
+		{"Missing column", tui.FormData{}, "ID,Comment,Remark", nil, "", true},
+		{"Too few columns", tui.FormData{}, "ID,Comment", nil, "", true},
+		{"Unknown template", tui.FormData{Template: "PDF"}, "", nil, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fd := tt.fd
+			err := applyFlags(&fd, headers, tt.columns)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyFlags() error = %v; wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(fd.SelectedHeaders, tt.wantHeaders) {
+				t.Errorf("applyFlags() selected %q; want %q", fd.SelectedHeaders, tt.wantHeaders)
+			}
+			if fd.Template != tt.wantTemplate {
+				t.Errorf("applyFlags() template = %q; want %q", fd.Template, tt.wantTemplate)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "review.csv")
+	if err := os.WriteFile(input, []byte("ID,Comment,Response\nRev1.1,Typo.,Fixed.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		expected   int
+		wantStdout string
+		wantStderr string
+	}{
+		{"Stdout", []string{"-i", input, "-template", "Markdown", "-output", "-"}, exitOK, "Rev1.1", ""},
+		{"Output file", []string{"-i", input, "-output", filepath.Join(dir, "rejoinder")}, exitOK, "", ""},
+		{"Help", []string{"-h"}, exitOK, "", "Usage:"},
+		{"Unknown flag", []string{"-bogus"}, exitUsage, "", "flag provided but not defined: -bogus"},
+		{"Missing input", []string{"-output", "-"}, exitUsage, "", "flag -i is required"},
+		{"Unknown template", []string{"-i", input, "-template", "PDF"}, exitUsage, "", "Run '"},
+		{"Missing column", []string{"-i", input, "-columns", "ID,Comment,Remark", "-output", "-"}, exitUsage, "", `column(s) ["Remark"] not found`},
+		{"Appendix without rounds", []string{"-i", input, "-appendix", "-output", "-"}, exitUsage, "", "-appendix requires -rounds"},
+		{"PDF to stdout", []string{"-i", input, "-pdf", "-output", "-"}, exitUsage, "", "-pdf cannot be combined"},
+		{"Unreadable input", []string{"-i", filepath.Join(dir, "missing.csv"), "-output", "-"}, exitError, "", "Error reading file:"},
+		{"Unwritable output", []string{"-i", input, "-output", filepath.Join(dir, "missing", "rejoinder")}, exitError, "", "Error saving output file:"},
+		{"Template export", []string{"template", "export", "Markdown"}, exitOK, "{{", ""},
+		{"Template without export", []string{"template"}, exitUsage, "", "Usage:"},
+		{"Template export of DOCX", []string{"template", "export", "DOCX"}, exitUsage, "", "cannot be exported"},
+		{"Watch without input", []string{"watch"}, exitUsage, "", "flag -i is required for watch"},
+		{"Watch unknown flag", []string{"watch", "-bogus"}, exitUsage, "", "flag provided but not defined: -bogus"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if got := run(tt.args, &stdout, &stderr); got != tt.expected {
+				t.Errorf("run(%q) = %d; want %d, stderr:\n%s", tt.args, got, tt.expected, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tt.wantStdout) {
+				t.Errorf("run(%q) stdout = %q; want it to contain %q", tt.args, stdout.String(), tt.wantStdout)
+			}
+			if !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("run(%q) stderr = %q; want it to contain %q", tt.args, stderr.String(), tt.wantStderr)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/andreas-bauer/rejoinderoo/rejoinder"
+)
+
+// runTemplate implements the template subcommand, which exports the built-in templates
+// as a starting point for custom templates. It returns the exit code, see run.
+func runTemplate(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("template export", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	outputFlag := fs.String("output", stdoutFilename, "file path of the exported template, use - for stdout")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), `Usage: %s template export [flags] <template>
+
+Exports the built-in template, one of %v,
+as a starting point for a custom template. Pass the edited file to
+-template-file (or set template_file in the config file) together with
+the matching -template.
+
+Flags:
+`, os.Args[0], rejoinder.TemplatesWith(rejoinder.OptionCustom))
+		fs.PrintDefaults()
+	}
+
+	if len(args) == 0 || args[0] != "export" {
+		fs.Usage()
+		return exitUsage
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return parseExitCode(err)
+	}
+	name := fs.Arg(0)
+	// flags may also follow the template name
+	if err := fs.Parse(fs.Args()[min(1, fs.NArg()):]); err != nil {
+		return parseExitCode(err)
+	}
+	if name == "" || fs.NArg() > 0 {
+		return usageError(stderr, fmt.Sprintf("template export requires exactly one template, one of %v", rejoinder.TemplatesWith(rejoinder.OptionCustom)))
+	}
+
+	tmpl, err := rejoinder.LookupTemplate(name)
+	if err != nil {
+		return usageError(stderr, err.Error())
+	}
+	builtin, ok := tmpl.Builtin()
+	if !ok {
+		return usageError(stderr, fmt.Sprintf("template %s cannot be exported, choose one of %v", tmpl.Name(), rejoinder.TemplatesWith(rejoinder.OptionCustom)))
+	}
+
+	if filename := strings.TrimSpace(*outputFlag); filename == stdoutFilename {
+		_, err = io.WriteString(stdout, builtin)
+	} else {
+		err = os.WriteFile(filename, []byte(builtin), 0644)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "Error saving template:", err)
+		return exitError
+	}
+	return exitOK
+}
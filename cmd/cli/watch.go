@@ -0,0 +1,205 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/andreas-bauer/rejoinderoo/internal/compile"
+	"github.com/andreas-bauer/rejoinderoo/internal/config"
+	"github.com/andreas-bauer/rejoinderoo/internal/tui"
+	"github.com/andreas-bauer/rejoinderoo/internal/watch"
+	"github.com/andreas-bauer/rejoinderoo/rejoinder"
+)
+
+// runWatch implements the watch subcommand, which regenerates the rejoinder
+// whenever the input file is saved. It returns the exit code, see run.
+func runWatch(args []string, stderr io.Writer) int {
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var gen genFlags
+	gen.register(fs, "file path of the generated rejoinder", "recompile the PDF after each change")
+	debounceFlag := fs.Duration("debounce", watch.DefaultDebounce, "time to wait for further saves before regenerating")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), `Usage: %s watch -i <file> [flags]
+
+Regenerates the rejoinder every time the input file is saved and prints
+which response IDs changed since the last run. Stop with Ctrl+C.
+
+Flags:
+`, os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return parseExitCode(err)
+	}
+
+	inFile := gen.inFile
+	if inFile == "" {
+		return usageError(stderr, "flag -i is required for watch")
+	}
+	if gen.output == stdoutFilename {
+		return usageError(stderr, "watch cannot write to stdout")
+	}
+
+	cfg, _, err := config.Discover(inFile)
+	if err != nil {
+		fmt.Fprintln(stderr, "Error reading config file:", err)
+		return exitError
+	}
+
+	tmpl, err := rejoinder.LookupTemplate(cmp.Or(gen.template, cfg.Template))
+	if err != nil {
+		return usageError(stderr, err.Error())
+	}
+	tmplName := tmpl.Name()
+	custom, err := loadCustomTemplate(tmpl, cmp.Or(gen.templateFile, cfg.TemplateFile))
+	if err != nil {
+		fmt.Fprintln(stderr, "Error loading custom template:", err)
+		return exitError
+	}
+	if gen.pdf && !compile.Supported(tmpl.Extension()) {
+		return usageError(stderr, fmt.Sprintf("template %s cannot be compiled to PDF", tmplName))
+	}
+
+	sheet := cmp.Or(gen.sheet, cfg.Sheet)
+	filename := cmp.Or(strings.TrimSpace(gen.output), "output")
+	filename = appendExtensionIfNotPresent(filename, tmpl.Extension())
+	escaping, err := rejoinder.ParseEscaping(cmp.Or(gen.escaping, cfg.EscapingFor(tmplName)))
+	if err != nil {
+		return usageError(stderr, err.Error())
+	}
+	scheme, err := rejoinder.ParseIDScheme(cmp.Or(gen.idScheme, cfg.IDScheme))
+	if err != nil {
+		return usageError(stderr, err.Error())
+	}
+	palette, err := rejoinder.ParsePalette(cmp.Or(gen.palette, cfg.Palette))
+	if err != nil {
+		return usageError(stderr, err.Error())
+	}
+	where, ord := cmp.Or(gen.where, cfg.Where), cmp.Or(gen.order, cfg.Order)
+	if err := (rejoinder.Selection{Where: where, Order: ord}).Validate(); err != nil {
+		return usageError(stderr, err.Error())
+	}
+	for _, name := range ignoredFlags(tmpl, fs) {
+		fmt.Fprintf(stderr, "Warning: template %s ignores -%s\n", tmpl.Name(), name)
+	}
+	roles, err := rejoinder.ParseRoles(gen.roles)
+	if err != nil {
+		return usageError(stderr, err.Error())
+	}
+	if len(roles) == 0 {
+		roles = configRoles(cfg)
+	}
+	opts := rejoinder.Options{
+		Meta:             rejoinder.Metadata(cfg.Metadata()),
+		CellMarkup:       gen.markup || cfg.CellMarkup,
+		Escaping:         escaping,
+		UnicodeFallbacks: cfg.Fallbacks(),
+		IDScheme:         scheme,
+		Palette:          palette,
+		Colors:           reviewerColors(cfg),
+		TableOfContents:  gen.toc || cfg.TOC,
+		Template:         custom,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var prev *watch.Snapshot
+	regenerate := func() {
+		doc, err := rejoinder.ReadFile(inFile, rejoinder.ReadOptions{Sheet: sheet})
+		if err != nil {
+			// e.g., the file is still being written; the next save triggers a new attempt
+			logWatch(stderr, "Error reading file, waiting for next save: %v", err)
+			return
+		}
+
+		fd := &tui.FormData{
+			SelectedHeaders: existingHeaders(cfg.Columns, doc.Headers()),
+			Template:        tmplName,
+			Roles:           roles,
+		}
+		if err := applyFlags(fd, doc.Headers(), gen.columns); err != nil {
+			logWatch(stderr, "Error: %v", err)
+			return
+		}
+		err = doc.Select(rejoinder.Selection{
+			Columns:  fd.SelectedHeaders,
+			Roles:    fd.Roles,
+			Where:    where,
+			Order:    ord,
+			IDScheme: scheme,
+		})
+		if err != nil {
+			logWatch(stderr, "Error: %v", err)
+			return
+		}
+		for _, msg := range tmpl.Warnings(doc, opts) {
+			logWatch(stderr, "Warning: %s", msg)
+		}
+
+		out, err := tmpl.RenderBytes(ctx, doc, opts)
+		if err != nil {
+			logWatch(stderr, "Error rendering template: %v", err)
+			return
+		}
+		if err := os.WriteFile(filename, out, 0644); err != nil {
+			logWatch(stderr, "Error saving output file: %v", err)
+			return
+		}
+
+		snap := watch.NewSnapshot(responses(doc))
+		if prev == nil {
+			logWatch(stderr, "Generated %s", filename)
+		} else {
+			logWatch(stderr, "Regenerated %s (%s)", filename, watch.Diff(*prev, snap))
+		}
+		prev = &snap
+
+		if gen.pdf {
+			pdf, err := compilePDF(filename, out, renderOrder(doc, opts))
+			if err != nil {
+				reportCompileError(stderr, err)
+				return
+			}
+			logWatch(stderr, "Compiled %s", pdf)
+		}
+	}
+
+	regenerate()
+	logWatch(stderr, "Watching %s for changes, press Ctrl+C to stop", inFile)
+
+	err = watch.New(inFile, *debounceFlag).Run(ctx, regenerate, func(err error) {
+		logWatch(stderr, "Error watching file: %v", err)
+	})
+	if err != nil {
+		fmt.Fprintln(stderr, "Error watching file:", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// logWatch prints a timestamped message of the watch mode to stderr.
+func logWatch(stderr io.Writer, format string, a ...any) {
+	fmt.Fprintf(stderr, "[%s] %s\n", time.Now().Format(time.TimeOnly), fmt.Sprintf(format, a...))
+}
+
+// responses returns the comments of the document as responses for a snapshot, see watch.Diff.
+func responses(doc *rejoinder.Document) []watch.Response {
+	var res []watch.Response
+	for _, c := range doc.Comments() {
+		parts := []string{c.Reviewer, c.Comment, c.Response}
+		for _, f := range c.Fields {
+			parts = append(parts, f.Name, f.Value)
+		}
+		res = append(res, watch.Response{ID: c.ID, Parts: parts})
+	}
+	return res
+}
@@ -0,0 +1,225 @@
+package compile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrNoCompiler is returned if no suitable toolchain is installed.
+var ErrNoCompiler = errors.New("no compiler found on PATH")
+
+// Compiler compiles a generated rejoinder to PDF with a locally installed toolchain.
+type Compiler struct {
+	// Name of the executable, e.g., latexmk or typst.
+	Name string
+	path string
+	// args returns the arguments to compile the given source file.
+	args func(src string) []string
+	// runs is the number of times the compiler is executed, e.g., to resolve references.
+	runs int
+	// errLine finds the line of the first error in the compiler log.
+	errLine func(log, src string) (line int, msg string)
+}
+
+// candidate describes a toolchain that is searched for on PATH.
+type candidate struct {
+	name    string
+	args    func(src string) []string
+	runs    int
+	errLine func(log, src string) (int, string)
+}
+
+// candidates lists the supported toolchains per source file extension in order of preference.
+var candidates = map[string][]candidate{
+	".tex": {
+		{
+			name: "latexmk",
+			args: func(src string) []string {
+				return []string{"-pdf", "-interaction=nonstopmode", "-halt-on-error", "-file-line-error", src}
+			},
+			runs:    1,
+			errLine: latexErrLine,
+		},
+		{
+			name: "pdflatex",
+			args: func(src string) []string {
+				return []string{"-interaction=nonstopmode", "-halt-on-error", "-file-line-error", src}
+			},
+			runs:    2,
+			errLine: latexErrLine,
+		},
+		{
+			name: "lualatex",
+			args: func(src string) []string {
+				return []string{"-interaction=nonstopmode", "-halt-on-error", "-file-line-error", src}
+			},
+			runs:    2,
+			errLine: latexErrLine,
+		},
+	},
+	".typ": {
+		{
+			name: "typst",
+			args: func(src string) []string {
+				return []string{"compile", src, pdfName(src)}
+			},
+			runs:    1,
+			errLine: typstErrLine,
+		},
+	},
+}
+
+// Supported reports whether files with the given extension can be compiled to PDF,
+// regardless of whether a toolchain is installed.
+func Supported(ext string) bool {
+	_, ok := candidates[strings.ToLower(ext)]
+	return ok
+}
+
+// Find returns the first installed compiler for source files with the given extension.
+func Find(ext string) (*Compiler, error) {
+	cs, ok := candidates[strings.ToLower(ext)]
+	if !ok {
+		return nil, fmt.Errorf("compiling %s files to PDF is not supported", ext)
+	}
+
+	var names []string
+	for _, c := range cs {
+		names = append(names, c.name)
+		if path, err := exec.LookPath(c.name); err == nil {
+			return &Compiler{
+				Name:    c.name,
+				path:    path,
+				args:    c.args,
+				runs:    c.runs,
+				errLine: c.errLine,
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: install one of %s", ErrNoCompiler, strings.Join(names, ", "))
+}
+
+// Compile compiles the source in a temporary directory and writes the resulting PDF
+// next to srcPath, using the same base name. It returns the path of the PDF.
+// If compilation fails, the returned error is an *Error with the compiler log and
+// the spreadsheet origin of the failing line, if it can be determined from the source map.
+func (c *Compiler) Compile(ctx context.Context, srcPath string, source []byte, sm SourceMap) (string, error) {
+	dir, err := os.MkdirTemp("", "rejoinderoo-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+
+	src := filepath.Base(srcPath)
+	if err := os.WriteFile(filepath.Join(dir, src), source, 0644); err != nil {
+		return "", err
+	}
+
+	for range c.runs {
+		cmd := exec.CommandContext(ctx, c.path, c.args(src)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			if ctx.Err() != nil {
+				return "", ctx.Err()
+			}
+			compileErr := &Error{
+				Compiler: c.Name,
+				Log:      string(out),
+				Err:      err,
+			}
+			compileErr.Line, compileErr.Message = c.errLine(compileErr.Log, src)
+			compileErr.Origin, _ = sm.Lookup(compileErr.Line)
+			return "", compileErr
+		}
+	}
+
+	pdf, err := os.ReadFile(filepath.Join(dir, pdfName(src)))
+	if err != nil {
+		return "", fmt.Errorf("%s did not produce a PDF: %w", c.Name, err)
+	}
+
+	pdfPath := pdfName(srcPath)
+	if err := os.WriteFile(pdfPath, pdf, 0644); err != nil {
+		return "", err
+	}
+	return pdfPath, nil
+}
+
+// Error describes a failed compilation.
+type Error struct {
+	Compiler string
+	Log      string
+	// Line in the generated source that caused the error, 0 if unknown.
+	Line    int
+	Message string
+	// Origin of the failing line in the spreadsheet, nil if unknown.
+	Origin *Origin
+	Err    error
+}
+
+func (e *Error) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s failed", e.Compiler)
+	if e.Line > 0 {
+		fmt.Fprintf(&sb, " at line %d", e.Line)
+	}
+	if e.Origin != nil {
+		fmt.Fprintf(&sb, " (spreadsheet row %d, ID %q)", e.Origin.Row, e.Origin.ID)
+	}
+	if e.Message != "" {
+		fmt.Fprintf(&sb, ": %s", e.Message)
+	} else {
+		fmt.Fprintf(&sb, ": %v", e.Err)
+	}
+	return sb.String()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// LogTail returns the last n lines of the compiler log.
+func (e *Error) LogTail(n int) string {
+	lines := strings.Split(strings.TrimRight(e.Log, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// latexErrLine finds the first error reported with -file-line-error, e.g.,
+// "./rejoinder.tex:42: Undefined control sequence."
+func latexErrLine(log, src string) (int, string) {
+	re := regexp.MustCompile(`(?m)^(?:\./)?` + regexp.QuoteMeta(src) + `:(\d+): (.*)$`)
+	m := re.FindStringSubmatch(log)
+	if m == nil {
+		return 0, ""
+	}
+	line, _ := strconv.Atoi(m[1])
+	return line, strings.TrimSpace(m[2])
+}
+
+// typstErrLine finds the first error diagnostic, e.g.,
+// "error: unclosed delimiter\n  ┌─ rejoinder.typ:42:5".
+func typstErrLine(log, src string) (int, string) {
+	re := regexp.MustCompile(`(?m)^error: (.*)\n\s*┌─ (?:\S*/)?` + regexp.QuoteMeta(src) + `:(\d+):\d+`)
+	m := re.FindStringSubmatch(log)
+	if m == nil {
+		return 0, ""
+	}
+	line, _ := strconv.Atoi(m[2])
+	return line, strings.TrimSpace(m[1])
+}
+
+// pdfName replaces the extension of the given file name with .pdf.
+func pdfName(name string) string {
+	return strings.TrimSuffix(name, filepath.Ext(name)) + ".pdf"
+}
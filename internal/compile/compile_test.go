@@ -0,0 +1,212 @@
+package compile
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestSourceMap(t *testing.T) {
+	source := strings.Join([]string{
+		`\newcommand{\response}[4]{`, // 1
+		`}`,                          // 2
+		`\response{`,                 // 3
+		`  colorRev1`,                // 4
+		`}`,                          // 5
+		`\response{`,                 // 6
+		`  colorRev2`,                // 7
+	}, "\n")
+	origins := []Origin{{Row: 2, ID: "Rev1.1"}, {Row: 7, ID: "Rev2.1"}}
+
+	sm := NewSourceMap([]byte(source), ".tex", origins)
+
+	tests := []struct {
+		line     int
+		expected *Origin
+	}{
+		{0, nil},
+		{1, nil},
+		{2, nil},
+		{3, &Origin{Row: 2, ID: "Rev1.1"}},
+		{5, &Origin{Row: 2, ID: "Rev1.1"}},
+		{6, &Origin{Row: 7, ID: "Rev2.1"}},
+		{100, &Origin{Row: 7, ID: "Rev2.1"}},
+	}
+
+	for _, tt := range tests {
+		got, ok := sm.Lookup(tt.line)
+		if tt.expected == nil {
+			if ok {
+				t.Errorf("Lookup(%d) = %+v; want no origin", tt.line, got)
+			}
+			continue
+		}
+		if !ok || *got != *tt.expected {
+			t.Errorf("Lookup(%d) = %+v, %v; want %+v", tt.line, got, ok, tt.expected)
+		}
+	}
+}
+
+func TestSourceMap_UnsupportedExtension(t *testing.T) {
+	sm := NewSourceMap([]byte("#response(\n"), ".md", []Origin{{Row: 2, ID: "R1"}})
+	if _, ok := sm.Lookup(1); ok {
+		t.Error("Lookup() for unsupported extension should not find an origin")
+	}
+}
+
+func TestLatexErrLine(t *testing.T) {
+	log := "This is pdfTeX\n(./rejoinder.tex\n./rejoinder.tex:42: Undefined control sequence.\nl.42 \\foo\n"
+	line, msg := latexErrLine(log, "rejoinder.tex")
+	if line != 42 || msg != "Undefined control sequence." {
+		t.Errorf("latexErrLine() = %d, %q; want 42, %q", line, msg, "Undefined control sequence.")
+	}
+
+	line, _ = latexErrLine("no errors here", "rejoinder.tex")
+	if line != 0 {
+		t.Errorf("latexErrLine() without error = %d; want 0", line)
+	}
+}
+
+func TestTypstErrLine(t *testing.T) {
+	log := "error: unclosed delimiter\n   ┌─ rejoinder.typ:17:3\n   │\n17 │   [\n"
+	line, msg := typstErrLine(log, "rejoinder.typ")
+	if line != 17 || msg != "unclosed delimiter" {
+		t.Errorf("typstErrLine() = %d, %q; want 17, %q", line, msg, "unclosed delimiter")
+	}
+}
+
+func TestError(t *testing.T) {
+	err := &Error{
+		Compiler: "pdflatex",
+		Line:     42,
+		Message:  "Undefined control sequence.",
+		Origin:   &Origin{Row: 5, ID: "Rev1.3"},
+		Err:      errors.New("exit status 1"),
+	}
+	want := `pdflatex failed at line 42 (spreadsheet row 5, ID "Rev1.3"): Undefined control sequence.`
+	if err.Error() != want {
+		t.Errorf("Error() = %q; want %q", err.Error(), want)
+	}
+
+	err = &Error{Compiler: "typst", Err: errors.New("exit status 1")}
+	want = "typst failed: exit status 1"
+	if err.Error() != want {
+		t.Errorf("Error() = %q; want %q", err.Error(), want)
+	}
+}
+
+func TestError_LogTail(t *testing.T) {
+	err := &Error{Log: "a\nb\nc\nd\n"}
+	if got := err.LogTail(2); got != "c\nd" {
+		t.Errorf("LogTail(2) = %q; want %q", got, "c\nd")
+	}
+	if got := err.LogTail(10); got != "a\nb\nc\nd" {
+		t.Errorf("LogTail(10) = %q; want %q", got, "a\nb\nc\nd")
+	}
+}
+
+func TestSupported(t *testing.T) {
+	tests := []struct {
+		ext      string
+		expected bool
+	}{
+		{".tex", true},
+		{".TEX", true},
+		{".typ", true},
+		{".md", false},
+		{".docx", false},
+	}
+
+	for _, tt := range tests {
+		if got := Supported(tt.ext); got != tt.expected {
+			t.Errorf("Supported(%q) = %v; want %v", tt.ext, got, tt.expected)
+		}
+	}
+}
+
+func TestFind_UnsupportedExtension(t *testing.T) {
+	if _, err := Find(".md"); err == nil {
+		t.Error("Find(\".md\") expected error")
+	}
+}
+
+func TestPdfName(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"rejoinder.tex", "rejoinder.pdf"},
+		{"dir/rejoinder.typ", "dir/rejoinder.pdf"},
+		{"noext", "noext.pdf"},
+	}
+
+	for _, tt := range tests {
+		if got := pdfName(tt.input); got != tt.expected {
+			t.Errorf("pdfName(%q) = %q; want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+// fakeTypst is a stand-in for the typst binary that fails if the source contains FAIL.
+const fakeTypst = `#!/bin/sh
+if grep -q FAIL "$2"; then
+  echo "error: boom"
+  echo "  ┌─ $2:3:1"
+  exit 1
+fi
+echo "%PDF-1.7" > "$3"
+`
+
+func TestCompile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake compiler requires a POSIX shell")
+	}
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "typst"), []byte(fakeTypst), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	c, err := Find(".typ")
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+
+	outDir := t.TempDir()
+	srcPath := filepath.Join(outDir, "rejoinder.typ")
+	origins := []Origin{{Row: 4, ID: "Rev1.1"}}
+
+	t.Run("success", func(t *testing.T) {
+		source := []byte("#let x = 1\n#response(\n  ok\n)\n")
+		pdfPath, err := c.Compile(context.Background(), srcPath, source, NewSourceMap(source, ".typ", origins))
+		if err != nil {
+			t.Fatalf("Compile() error = %v", err)
+		}
+		if pdfPath != filepath.Join(outDir, "rejoinder.pdf") {
+			t.Errorf("Compile() = %q; want PDF next to source", pdfPath)
+		}
+		if _, err := os.Stat(pdfPath); err != nil {
+			t.Errorf("Compile() did not write PDF: %v", err)
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		source := []byte("#let x = 1\n#response(\n  FAIL\n)\n")
+		_, err := c.Compile(context.Background(), srcPath, source, NewSourceMap(source, ".typ", origins))
+
+		var compileErr *Error
+		if !errors.As(err, &compileErr) {
+			t.Fatalf("Compile() error = %v; want *Error", err)
+		}
+		if compileErr.Line != 3 || compileErr.Message != "boom" {
+			t.Errorf("Compile() error line = %d, message = %q; want 3, boom", compileErr.Line, compileErr.Message)
+		}
+		if compileErr.Origin == nil || *compileErr.Origin != (Origin{Row: 4, ID: "Rev1.1"}) {
+			t.Errorf("Compile() error origin = %+v; want row 4, ID Rev1.1", compileErr.Origin)
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package compile
+
+import (
+	"bufio"
+	"bytes"
+	"sort"
+	"strings"
+)
+
+// Origin identifies the spreadsheet record that produced a part of the generated source.
+type Origin struct {
+	Row int
+	ID  string
+}
+
+// SourceMap maps lines of a generated source file back to spreadsheet records.
+type SourceMap struct {
+	starts  []int // first line of each response box, ascending
+	origins []Origin
+}
+
+// boxMarkers are the line prefixes that start a response box in the generated source.
+var boxMarkers = map[string]string{
+	".tex": `\response{`,
+	".typ": `#response(`,
+}
+
+// NewSourceMap creates a source map for the generated source with the given extension.
+// The n-th response box in the source is mapped to the n-th origin, i.e., the origins are
+// the records in the order they are rendered.
+func NewSourceMap(source []byte, ext string, origins []Origin) SourceMap {
+	marker, ok := boxMarkers[strings.ToLower(ext)]
+	if !ok {
+		return SourceMap{}
+	}
+
+	var sm SourceMap
+	scanner := bufio.NewScanner(bytes.NewReader(source))
+	scanner.Buffer(nil, len(source)+1)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if !strings.HasPrefix(strings.TrimSpace(scanner.Text()), marker) {
+			continue
+		}
+		idx := len(sm.starts)
+		if idx >= len(origins) {
+			break
+		}
+		sm.starts = append(sm.starts, line)
+		sm.origins = append(sm.origins, origins[idx])
+	}
+	return sm
+}
+
+// Lookup returns the origin of the given 1-based line.
+// Lines before the first response box, e.g., the preamble, have no origin.
+func (sm SourceMap) Lookup(line int) (*Origin, bool) {
+	i := sort.SearchInts(sm.starts, line+1) - 1
+	if line < 1 || i < 0 {
+		return nil, false
+	}
+	o := sm.origins[i]
+	return &o, true
+}
@@ -0,0 +1,206 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/andreas-bauer/rejoinderoo/internal/filter"
+	"github.com/andreas-bauer/rejoinderoo/internal/order"
+	"github.com/andreas-bauer/rejoinderoo/internal/reader"
+	"github.com/andreas-bauer/rejoinderoo/internal/templates/common"
+	"gopkg.in/yaml.v3"
+)
+
+// FileNames lists the names of project config files in the order they are searched for.
+var FileNames = []string{"rejoinderoo.yaml", "rejoinderoo.yml"}
+
+// Config represents the project config file with metadata about the paper
+// and default selections for generating the rejoinder.
+type Config struct {
+	Title        string   `yaml:"title"`
+	ManuscriptID string   `yaml:"manuscript_id"`
+	Authors      []string `yaml:"authors"`
+	Editor       string   `yaml:"editor"`
+	Venue        string   `yaml:"venue"`
+	CoverLetter  string   `yaml:"cover_letter"`
+	KeyChanges   []string `yaml:"key_changes"`
+
+	Sheet      string   `yaml:"sheet"`
+	Columns    []string `yaml:"columns"`
+	Template   string   `yaml:"template"`
+	CellMarkup bool     `yaml:"cell_markup"`
+	TOC        bool     `yaml:"toc"`
+	// Escaping maps template names to their escaping mode, e.g., "latex: math".
+	Escaping map[string]string `yaml:"escaping"`
+	// UnicodeFallbacks maps single characters to their replacement in LaTeX, e.g., "✓: \\checkmark".
+	UnicodeFallbacks map[string]string `yaml:"unicode_fallbacks"`
+	// IDScheme is the name of a preset or a regular expression with the named groups
+	// "reviewer" and optionally "comment" to parse the comment IDs.
+	IDScheme string `yaml:"id_scheme"`
+	// Palette is the name of the color palette for the reviewers.
+	Palette string `yaml:"palette"`
+	// Colors overrides the palette color of individual reviewers, e.g., "Rev1: '#E69F00'".
+	Colors map[string]string `yaml:"colors"`
+	// Order is the order of the responses, e.g., "id" or "reviewers=Editor, R2, R1".
+	Order string `yaml:"order"`
+	// Where keeps only the rows that match a filter, e.g., "Status!=done".
+	Where string `yaml:"where"`
+	// Roles maps column headers to their role, e.g., "Remark: comment".
+	Roles map[string]string `yaml:"roles"`
+	// TemplateFile is the path of a custom template that replaces the built-in one.
+	// Load resolves relative paths against the directory of the config file.
+	TemplateFile string `yaml:"template_file"`
+}
+
+// Discover looks for a project config file in the directory of the given input file.
+// It returns the parsed config and its path, or an empty config and an empty path
+// if no config file exists.
+func Discover(inputFile string) (*Config, string, error) {
+	dir := filepath.Dir(inputFile)
+	for _, name := range FileNames {
+		path := filepath.Join(dir, name)
+		cfg, err := Load(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		return cfg, path, nil
+	}
+	return &Config{}, "", nil
+}
+
+// Load reads and parses the config file at the given path.
+func Load(path string) (*Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	cfg, err := Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if cfg.TemplateFile != "" && !filepath.IsAbs(cfg.TemplateFile) {
+		cfg.TemplateFile = filepath.Join(filepath.Dir(path), cfg.TemplateFile)
+	}
+	return cfg, nil
+}
+
+// Parse parses a config from YAML. Unknown keys are reported as error
+// to catch typos early.
+func Parse(r io.Reader) (*Config, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{}
+	if len(bytes.TrimSpace(content)) == 0 {
+		return cfg, nil
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil {
+		return nil, err
+	}
+	for char := range cfg.UnicodeFallbacks {
+		if utf8.RuneCountInString(char) != 1 {
+			return nil, fmt.Errorf("unicode_fallbacks: key %q must be a single character", char)
+		}
+	}
+	if _, err := common.ParseIDScheme(cfg.IDScheme); err != nil {
+		return nil, fmt.Errorf("id_scheme: %w", err)
+	}
+	if _, err := common.ParsePalette(cfg.Palette); err != nil {
+		return nil, fmt.Errorf("palette: %w", err)
+	}
+	if _, err := order.Parse(cfg.Order); err != nil {
+		return nil, fmt.Errorf("order: %w", err)
+	}
+	if _, err := filter.Parse(cfg.Where); err != nil {
+		return nil, fmt.Errorf("where: %w", err)
+	}
+	for header, role := range cfg.Roles {
+		if _, err := reader.ParseRole(role); err != nil {
+			return nil, fmt.Errorf("roles: column %s: %w", header, err)
+		}
+	}
+	for reviewer, color := range cfg.Colors {
+		if _, err := common.ParseColor(color); err != nil {
+			return nil, fmt.Errorf("colors: reviewer %s: %w", reviewer, err)
+		}
+	}
+	return cfg, nil
+}
+
+// EscapingFor returns the configured escaping mode of the template, or an empty string.
+// Template names are compared case-insensitively.
+func (c *Config) EscapingFor(template string) string {
+	for name, mode := range c.Escaping {
+		if strings.EqualFold(name, strings.TrimSpace(template)) {
+			return mode
+		}
+	}
+	return ""
+}
+
+// Fallbacks returns the Unicode fallbacks of the config for use in templates.
+func (c *Config) Fallbacks() map[rune]string {
+	if len(c.UnicodeFallbacks) == 0 {
+		return nil
+	}
+	res := make(map[rune]string, len(c.UnicodeFallbacks))
+	for char, replacement := range c.UnicodeFallbacks {
+		r, _ := utf8.DecodeRuneInString(char)
+		res[r] = replacement
+	}
+	return res
+}
+
+// ReviewerColors returns the per-reviewer color overrides of the config for use in templates.
+func (c *Config) ReviewerColors() map[string]common.Color {
+	if len(c.Colors) == 0 {
+		return nil
+	}
+	res := make(map[string]common.Color, len(c.Colors))
+	for reviewer, color := range c.Colors {
+		res[reviewer], _ = common.ParseColor(color)
+	}
+	return res
+}
+
+// ColumnRoles returns the column roles of the config, keyed by header.
+func (c *Config) ColumnRoles() map[string]reader.Role {
+	if len(c.Roles) == 0 {
+		return nil
+	}
+	res := make(map[string]reader.Role, len(c.Roles))
+	for header, role := range c.Roles {
+		res[header], _ = reader.ParseRole(role)
+	}
+	return res
+}
+
+// Metadata returns the paper metadata of the config for use in templates.
+func (c *Config) Metadata() common.Metadata {
+	return common.Metadata{
+		Title:        c.Title,
+		ManuscriptID: c.ManuscriptID,
+		Authors:      c.Authors,
+		Editor:       c.Editor,
+		Venue:        c.Venue,
+		CoverLetter:  c.CoverLetter,
+		KeyChanges:   c.KeyChanges,
+	}
+}
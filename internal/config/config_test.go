@@ -0,0 +1,261 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andreas-bauer/rejoinderoo/internal/reader"
+	"github.com/andreas-bauer/rejoinderoo/internal/templates/common"
+)
+
+const exampleConfig = `
+title: Guidelines for Code Review of Test Artifacts
+manuscript_id: EMSE-D-25-00042
+authors:
+  - Andreas Bauer
+  - Maria Doe
+editor: Prof. Smith
+venue: Empirical Software Engineering
+cover_letter: |
+  Thank you for the feedback.
+key_changes:
+  - Switched Tables 3 and 4
+  - Extended the threats to validity
+sheet: Round 2
+columns: [ID, Comment, Response, Action]
+template: Typst
+cell_markup: true
+escaping:
+  LaTeX: math
+unicode_fallbacks:
+  "✓": "\\checkmark"
+`
+
+func TestParse(t *testing.T) {
+	cfg, err := Parse(strings.NewReader(exampleConfig))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := &Config{
+		Title:            "Guidelines for Code Review of Test Artifacts",
+		ManuscriptID:     "EMSE-D-25-00042",
+		Authors:          []string{"Andreas Bauer", "Maria Doe"},
+		Editor:           "Prof. Smith",
+		Venue:            "Empirical Software Engineering",
+		CoverLetter:      "Thank you for the feedback.\n",
+		KeyChanges:       []string{"Switched Tables 3 and 4", "Extended the threats to validity"},
+		Sheet:            "Round 2",
+		Columns:          []string{"ID", "Comment", "Response", "Action"},
+		Template:         "Typst",
+		CellMarkup:       true,
+		Escaping:         map[string]string{"LaTeX": "math"},
+		UnicodeFallbacks: map[string]string{"✓": "\\checkmark"},
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("Parse() = %+v; want %+v", cfg, want)
+	}
+}
+
+func TestParse_Empty(t *testing.T) {
+	cfg, err := Parse(strings.NewReader("  \n"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if !reflect.DeepEqual(cfg, &Config{}) {
+		t.Errorf("Parse() = %+v; want empty config", cfg)
+	}
+}
+
+func TestParse_UnknownField(t *testing.T) {
+	_, err := Parse(strings.NewReader("titel: typo\n"))
+	if err == nil {
+		t.Fatal("Parse() expected error for unknown field")
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "reviews.xlsx")
+
+	cfg, path, err := Discover(input)
+	if err != nil {
+		t.Fatalf("Discover() without config error = %v", err)
+	}
+	if path != "" || !reflect.DeepEqual(cfg, &Config{}) {
+		t.Errorf("Discover() without config = %+v, %q; want empty config and path", cfg, path)
+	}
+
+	cfgPath := filepath.Join(dir, "rejoinderoo.yml")
+	if err := os.WriteFile(cfgPath, []byte("title: My Paper\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, path, err = Discover(input)
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	if path != cfgPath || cfg.Title != "My Paper" {
+		t.Errorf("Discover() = %+v, %q; want title 'My Paper' from %q", cfg, path, cfgPath)
+	}
+}
+
+func TestLoad_TemplateFile(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"None", "title: My Paper\n", ""},
+		{"Relative", "template_file: templates/house.tmpl\n", filepath.Join(dir, "templates", "house.tmpl")},
+		{"Absolute", "template_file: /opt/house.tmpl\n", "/opt/house.tmpl"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "rejoinderoo.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := Load(path)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if cfg.TemplateFile != tt.expected {
+				t.Errorf("Load() TemplateFile = %q; want %q", cfg.TemplateFile, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConfig_Metadata(t *testing.T) {
+	cfg, err := Parse(strings.NewReader(exampleConfig))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	meta := cfg.Metadata()
+	if meta.Title != cfg.Title || meta.ManuscriptID != cfg.ManuscriptID || meta.Editor != cfg.Editor ||
+		meta.Venue != cfg.Venue || meta.CoverLetter != cfg.CoverLetter ||
+		!reflect.DeepEqual(meta.Authors, cfg.Authors) || !reflect.DeepEqual(meta.KeyChanges, cfg.KeyChanges) {
+		t.Errorf("Metadata() = %+v; does not match config %+v", meta, cfg)
+	}
+}
+
+func TestConfig_EscapingFor(t *testing.T) {
+	cfg := &Config{Escaping: map[string]string{"LaTeX": "math"}}
+
+	tests := []struct {
+		template string
+		expected string
+	}{
+		{"LaTeX", "math"},
+		{"latex", "math"},
+		{"Typst", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			if got := cfg.EscapingFor(tt.template); got != tt.expected {
+				t.Errorf("EscapingFor(%q) = %q; want %q", tt.template, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParse_InvalidUnicodeFallback(t *testing.T) {
+	_, err := Parse(strings.NewReader("unicode_fallbacks:\n  ab: x\n"))
+	if err == nil {
+		t.Errorf("Parse() expected error for key with several characters")
+	}
+}
+
+func TestParse_IDScheme(t *testing.T) {
+	cfg, err := Parse(strings.NewReader("id_scheme: reviewer-comment\n"))
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+	if cfg.IDScheme != "reviewer-comment" {
+		t.Errorf("Parse() IDScheme = %q; want %q", cfg.IDScheme, "reviewer-comment")
+	}
+
+	_, err = Parse(strings.NewReader("id_scheme: '^R(\\d+)$'\n"))
+	if err == nil {
+		t.Errorf("Parse() expected error for ID scheme without reviewer group")
+	}
+}
+
+func TestParse_Colors(t *testing.T) {
+	cfg, err := Parse(strings.NewReader("palette: pastel\ncolors:\n  Rev1: '#e69f00'\n  Rev2: abc\n"))
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+	if cfg.Palette != "pastel" {
+		t.Errorf("Parse() Palette = %q; want %q", cfg.Palette, "pastel")
+	}
+	want := map[string]common.Color{"Rev1": "#E69F00", "Rev2": "#AABBCC"}
+	if got := cfg.ReviewerColors(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ReviewerColors() = %v; want %v", got, want)
+	}
+
+	for _, input := range []string{"palette: neon\n", "colors:\n  Rev1: red\n"} {
+		if _, err := Parse(strings.NewReader(input)); err == nil {
+			t.Errorf("Parse(%q) expected error", input)
+		}
+	}
+}
+
+func TestParse_Order(t *testing.T) {
+	cfg, err := Parse(strings.NewReader("order: reviewers=Editor, R2, R1\n"))
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+	if cfg.Order != "reviewers=Editor, R2, R1" {
+		t.Errorf("Parse() Order = %q", cfg.Order)
+	}
+	if _, err := Parse(strings.NewReader("order: random\n")); err == nil {
+		t.Errorf("Parse() expected error for unknown order")
+	}
+}
+
+func TestParse_Where(t *testing.T) {
+	cfg, err := Parse(strings.NewReader("where: Status!=done\n"))
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+	if cfg.Where != "Status!=done" {
+		t.Errorf("Parse() Where = %q", cfg.Where)
+	}
+	if _, err := Parse(strings.NewReader("where: Status\n")); err == nil {
+		t.Errorf("Parse() expected error for invalid filter")
+	}
+}
+
+func TestParse_Roles(t *testing.T) {
+	cfg, err := Parse(strings.NewReader("roles:\n  No: id\n  Remark: Comment\n  Status: hidden\n"))
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+	want := map[string]reader.Role{"No": reader.IDRole, "Remark": reader.CommentRole, "Status": reader.HiddenRole}
+	if got := cfg.ColumnRoles(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ColumnRoles() = %v; want %v", got, want)
+	}
+	if _, err := Parse(strings.NewReader("roles:\n  Status: secret\n")); err == nil {
+		t.Errorf("Parse() expected error for unknown role")
+	}
+}
+
+func TestConfig_Fallbacks(t *testing.T) {
+	cfg := &Config{UnicodeFallbacks: map[string]string{"✓": "\\checkmark", "🎉": ":)"}}
+	want := map[rune]string{'✓': "\\checkmark", '🎉': ":)"}
+	if got := cfg.Fallbacks(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Fallbacks() = %v; want %v", got, want)
+	}
+	if got := (&Config{}).Fallbacks(); got != nil {
+		t.Errorf("Fallbacks() = %v; want nil", got)
+	}
+}
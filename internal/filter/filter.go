@@ -0,0 +1,238 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/andreas-bauer/rejoinderoo/internal/reader"
+)
+
+// Op is the comparison of a condition.
+type Op int
+
+const (
+	// Equal matches cells with the value.
+	Equal Op = iota
+	// NotEqual matches cells without the value.
+	NotEqual
+	// In matches cells with one of the values.
+	In
+	// NotIn matches cells with none of the values.
+	NotIn
+)
+
+var opNames = map[Op]string{
+	Equal:    "=",
+	NotEqual: "!=",
+	In:       "in",
+	NotIn:    "not in",
+}
+
+func (op Op) String() string {
+	return opNames[op]
+}
+
+// Condition compares the cells of a column with one or more values.
+// Values are compared case-insensitively and without surrounding spaces.
+type Condition struct {
+	Column string
+	Op     Op
+	Values []string
+}
+
+// Match reports whether the cell value satisfies the condition.
+func (c Condition) Match(value string) bool {
+	value = strings.TrimSpace(value)
+	found := slices.ContainsFunc(c.Values, func(v string) bool {
+		return strings.EqualFold(v, value)
+	})
+	if c.Op == NotEqual || c.Op == NotIn {
+		return !found
+	}
+	return found
+}
+
+// String returns the condition in the form accepted by Parse.
+func (c Condition) String() string {
+	if c.Op == In || c.Op == NotIn {
+		values := make([]string, len(c.Values))
+		for i, v := range c.Values {
+			values[i] = v
+			if strings.ContainsAny(v, ",()") {
+				values[i] = `"` + v + `"`
+			}
+		}
+		return fmt.Sprintf("%s %s (%s)", c.Column, c.Op, strings.Join(values, ", "))
+	}
+	if strings.Contains(strings.ToLower(c.Values[0]), " and ") {
+		return c.Column + c.Op.String() + `"` + c.Values[0] + `"`
+	}
+	return c.Column + c.Op.String() + c.Values[0]
+}
+
+// Filter is a transformation that keeps the records of tabular data that satisfy all conditions.
+// The zero value keeps all records.
+type Filter struct {
+	Conditions []Condition
+}
+
+var (
+	listExpr = regexp.MustCompile(`(?is)^(.+?)\s+(not\s+in|in)\s*\((.*)\)$`)
+	andExpr  = regexp.MustCompile(`(?i)^\s+and\s+`)
+)
+
+// Parse parses a filter of conditions joined by "and", each of the form "<header>=<value>",
+// "<header>!=<value>", "<header> in (<value>, <value>, ...)", or "<header> not in (...)",
+// e.g., "Status=done" or "Status!=done and Responsible in (Andreas, Maria)".
+// Values may be quoted. An empty expression keeps all records.
+func Parse(expr string) (Filter, error) {
+	var f Filter
+	if strings.TrimSpace(expr) == "" {
+		return f, nil
+	}
+	for _, part := range splitAnd(expr) {
+		c, err := parseCondition(strings.TrimSpace(part))
+		if err != nil {
+			return Filter{}, err
+		}
+		f.Conditions = append(f.Conditions, c)
+	}
+	return f, nil
+}
+
+func parseCondition(expr string) (Condition, error) {
+	if m := listExpr.FindStringSubmatch(expr); m != nil {
+		c := Condition{Column: strings.TrimSpace(m[1]), Op: In}
+		if strings.Contains(strings.ToLower(m[2]), "not") {
+			c.Op = NotIn
+		}
+		for _, v := range splitValues(m[3]) {
+			if v = unquote(v); v != "" {
+				c.Values = append(c.Values, v)
+			}
+		}
+		if len(c.Values) == 0 {
+			return Condition{}, fmt.Errorf("condition %q requires at least one value, e.g., Responsible in (Andreas, Maria)", expr)
+		}
+		return c, nil
+	}
+
+	column, value, found := strings.Cut(expr, "=")
+	if !found {
+		return Condition{}, fmt.Errorf("condition %q is not supported, use <column>=<value>, <column>!=<value>, or <column> in (<values>)", expr)
+	}
+	c := Condition{Column: strings.TrimSpace(column), Op: Equal, Values: []string{unquote(value)}}
+	if col, ok := strings.CutSuffix(c.Column, "!"); ok {
+		c.Column, c.Op = strings.TrimSpace(col), NotEqual
+	}
+	if c.Column == "" {
+		return Condition{}, fmt.Errorf("condition %q requires a column", expr)
+	}
+	return c, nil
+}
+
+// splitAnd splits the expression at "and" outside of quotes and value lists.
+func splitAnd(expr string) []string {
+	var parts []string
+	var quote byte
+	depth, start := 0, 0
+	for i := 0; i < len(expr); i++ {
+		switch c := expr[i]; {
+		case c == quote:
+			quote = 0
+		case quote != 0:
+			// "and" inside quotes belongs to the value
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth = max(depth-1, 0)
+		case c == ' ' || c == '\t':
+			if depth > 0 {
+				continue
+			}
+			if loc := andExpr.FindStringIndex(expr[i:]); loc != nil {
+				parts = append(parts, expr[start:i])
+				i += loc[1] - 1
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, expr[start:])
+}
+
+// splitValues splits a list of values at commas outside of quotes.
+func splitValues(list string) []string {
+	var values []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(list); i++ {
+		switch c := list[i]; {
+		case c == quote:
+			quote = 0
+		case quote != 0:
+			// commas inside quotes belong to the value
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ',':
+			values = append(values, list[start:i])
+			start = i + 1
+		}
+	}
+	return append(values, list[start:])
+}
+
+// unquote trims spaces and a pair of surrounding quotes from the value.
+func unquote(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// String returns the filter in the form accepted by Parse.
+func (f Filter) String() string {
+	conds := make([]string, len(f.Conditions))
+	for i, c := range f.Conditions {
+		conds[i] = c.String()
+	}
+	return strings.Join(conds, " and ")
+}
+
+// Apply removes the records of the tabular data that do not satisfy all conditions.
+func (f Filter) Apply(td *reader.TabularData) error {
+	if len(f.Conditions) == 0 {
+		return nil
+	}
+	cols := make([]int, len(f.Conditions))
+	for i, c := range f.Conditions {
+		cols[i] = slices.Index(td.Headers, c.Column)
+		if cols[i] < 0 {
+			return fmt.Errorf("column %q not found", c.Column)
+		}
+	}
+
+	var keep []int
+	for i, rec := range td.Records {
+		match := true
+		for j, c := range f.Conditions {
+			var value string
+			if cols[j] < len(rec) {
+				value = rec[cols[j]]
+			}
+			if !c.Match(value) {
+				match = false
+				break
+			}
+		}
+		if match {
+			keep = append(keep, i)
+		}
+	}
+	td.Reorder(keep)
+	return nil
+}
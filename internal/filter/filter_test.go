@@ -0,0 +1,118 @@
+package filter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/andreas-bauer/rejoinderoo/internal/reader"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected []Condition
+		wantErr  bool
+	}{
+		{"", nil, false},
+		{"Status=done", []Condition{{"Status", Equal, []string{"done"}}}, false},
+		{" Status = 'in progress' ", []Condition{{"Status", Equal, []string{"in progress"}}}, false},
+		{"Status!=done", []Condition{{"Status", NotEqual, []string{"done"}}}, false},
+		{"Status=", []Condition{{"Status", Equal, []string{""}}}, false},
+		{"Comment=a=b", []Condition{{"Comment", Equal, []string{"a=b"}}}, false},
+		{"Responsible in (Andreas, Maria)", []Condition{{"Responsible", In, []string{"Andreas", "Maria"}}}, false},
+		{"Responsible NOT IN (\"Bauer, A.\")", []Condition{{"Responsible", NotIn, []string{"Bauer, A."}}}, false},
+		{"Status!=done and Responsible in (Anderson and Co, Maria) AND Where=Section 4", []Condition{
+			{"Status", NotEqual, []string{"done"}},
+			{"Responsible", In, []string{"Anderson and Co", "Maria"}},
+			{"Where", Equal, []string{"Section 4"}},
+		}, false},
+		{`Title="Q and A"`, []Condition{{"Title", Equal, []string{"Q and A"}}}, false},
+		{`Title!='Q and A (draft)' and Status=done`, []Condition{
+			{"Title", NotEqual, []string{"Q and A (draft)"}},
+			{"Status", Equal, []string{"done"}},
+		}, false},
+		{"Status", nil, true},
+		{"=done", nil, true},
+		{"Responsible in ( , )", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := Parse(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v; wantErr %v", tt.expr, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got.Conditions, tt.expected) {
+				t.Errorf("Parse(%q) = %+v; want %+v", tt.expr, got.Conditions, tt.expected)
+			}
+			if err == nil {
+				if again, _ := Parse(got.String()); !reflect.DeepEqual(again, got) {
+					t.Errorf("Parse(%q) = %+v; want %+v", got.String(), again, got)
+				}
+			}
+		})
+	}
+}
+
+func TestFilter_Apply(t *testing.T) {
+	newTable := func() *reader.TabularData {
+		return &reader.TabularData{
+			Headers: []string{"ID", "Status", "Responsible"},
+			Records: [][]string{
+				{"Rev1.1", "done", "Andreas"},
+				{"Rev1.2", "open", "Maria"},
+				{"Rev1.3", "", "Jonas"},
+				{"Rev2.1", "Done ", "Maria"},
+				{"Rev2.2", "open"},
+			},
+		}
+	}
+
+	tests := []struct {
+		expr     string
+		expected []string
+		rows     []int
+	}{
+		{"", []string{"Rev1.1", "Rev1.2", "Rev1.3", "Rev2.1", "Rev2.2"}, []int{2, 3, 4, 5, 6}},
+		{"Status=done", []string{"Rev1.1", "Rev2.1"}, []int{2, 5}},
+		{"Status!=done", []string{"Rev1.2", "Rev1.3", "Rev2.2"}, []int{3, 4, 6}},
+		{"Status=", []string{"Rev1.3"}, []int{4}},
+		{"Responsible in (andreas, Maria)", []string{"Rev1.1", "Rev1.2", "Rev2.1"}, []int{2, 3, 5}},
+		{"Responsible not in (Maria)", []string{"Rev1.1", "Rev1.3", "Rev2.2"}, []int{2, 4, 6}},
+		{"Status=open and Responsible=Maria", []string{"Rev1.2"}, []int{3}},
+		{"Status=blocked", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			f, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.expr, err)
+			}
+			td := newTable()
+			if err := f.Apply(td); err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			var ids []string
+			var rows []int
+			for i, rec := range td.Records {
+				ids = append(ids, rec[0])
+				rows = append(rows, td.Row(i))
+			}
+			if !reflect.DeepEqual(ids, tt.expected) {
+				t.Errorf("Apply() IDs = %v; want %v", ids, tt.expected)
+			}
+			if !reflect.DeepEqual(rows, tt.rows) {
+				t.Errorf("Apply() rows = %v; want %v", rows, tt.rows)
+			}
+		})
+	}
+}
+
+func TestFilter_ApplyMissingColumn(t *testing.T) {
+	f, _ := Parse("Priority=high")
+	td := &reader.TabularData{Headers: []string{"ID"}, Records: [][]string{{"Rev1.1"}}}
+	if err := f.Apply(td); err == nil {
+		t.Errorf("Apply() expected error for missing column")
+	}
+}
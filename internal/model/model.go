@@ -0,0 +1,222 @@
+// Package model holds the rejoinder that templates render: the comments grouped by reviewer
+// together with the authors' responses. It is built once from the tabular data and the roles
+// of its columns, so that templates do not depend on the column positions of the input.
+package model
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/andreas-bauer/rejoinderoo/internal/reader"
+	"github.com/andreas-bauer/rejoinderoo/internal/templates/common"
+)
+
+// Rejoinder holds the comments of the reviewers in the order in which the reviewers first appear.
+type Rejoinder struct {
+	Meta      common.Metadata
+	Columns   Columns
+	Reviewers []Reviewer
+}
+
+// Columns holds the headers of the parts of a comment. Details are the headers of the response
+// and the extra columns in the order in which templates render them below the comment, see reader.Layout.
+type Columns struct {
+	ID          string
+	Reviewer    string
+	HasReviewer bool
+	Comment     string
+	HasComment  bool
+	Response    string
+	HasResponse bool
+	Details     []string
+}
+
+// Reviewer holds the comments of one reviewer. The ID is extracted by the ID scheme or taken from
+// the reviewer column; it is empty for comments without a reviewer.
+// ColorID identifies the color of the reviewer in color names, see common.ColorIDs.
+type Reviewer struct {
+	ID       string
+	Name     string
+	Color    common.Color
+	ColorID  string
+	Comments []Comment
+}
+
+// Comment is a reviewer's comment together with the authors' response.
+// Number is the comment number that the ID scheme extracts or, if there is none, the whole ID.
+// Extra holds the cells of the other detail columns by header.
+// Index is the index of the record in the tabular data, e.g., to look up labels.
+type Comment struct {
+	ID       Cell
+	Number   string
+	Reviewer string
+	Comment  Cell
+	Response Cell
+	Extra    map[string]Cell
+	Index    int
+	Row      int
+}
+
+// Cell is the text of a column of a comment and its formatting, if any.
+type Cell struct {
+	Header string
+	Text   string
+	Rich   reader.RichText
+}
+
+// New builds the rejoinder from the tabular data. The ID scheme of the options, or the reviewer column,
+// groups the comments by reviewer, and the palette of the options colors the reviewers.
+// Empty records are skipped, and tabular data without columns yields a rejoinder without reviewers.
+func New(td *reader.TabularData, opts common.Options) Rejoinder {
+	layout := td.Layout()
+	if layout.ID < 0 {
+		return Rejoinder{Meta: opts.Meta}
+	}
+	scheme := IDScheme(td, opts.IDScheme)
+	response := ResponseColumn(td)
+	groups := scheme.Group(td.Records)
+
+	ids := make([]string, len(groups))
+	for i, g := range groups {
+		ids[i] = g.Reviewer
+	}
+	colors := opts.ReviewerColors(ids)
+	colorIDs := common.ColorIDs(ids)
+
+	res := Rejoinder{
+		Meta:      opts.Meta,
+		Columns:   newColumns(td.Headers, layout, td.Column(reader.ReviewerRole), response),
+		Reviewers: make([]Reviewer, len(groups)),
+	}
+	for i, g := range groups {
+		rev := Reviewer{ID: g.Reviewer, Name: common.ReviewerName(g.Reviewer), Color: colors[i], ColorID: colorIDs[i]}
+		for _, idx := range g.Records {
+			rev.Comments = append(rev.Comments, newComment(td, idx, g.Reviewer, layout, response, scheme))
+		}
+		res.Reviewers[i] = rev
+	}
+	return res
+}
+
+// IDScheme returns the ID scheme that takes the reviewer from the reviewer column of the tabular data, if any.
+func IDScheme(td *reader.TabularData, scheme *common.IDScheme) *common.IDScheme {
+	return scheme.WithReviewerColumn(td.Column(reader.ReviewerRole))
+}
+
+// ResponseColumn returns the column with the response role or, without one,
+// the first detail column without a role. It returns -1 if there is neither.
+func ResponseColumn(td *reader.TabularData) int {
+	roles := td.ColumnRoles()
+	if i := slices.Index(roles, reader.ResponseRole); i >= 0 {
+		return i
+	}
+	details := td.Layout().Details
+	if i := slices.IndexFunc(details, func(j int) bool { return roles[j] == reader.NoRole }); i >= 0 {
+		return details[i]
+	}
+	return -1
+}
+
+func newColumns(headers []string, layout reader.Layout, reviewer, response int) Columns {
+	res := Columns{
+		ID:          headers[layout.ID],
+		HasReviewer: reviewer >= 0,
+		HasComment:  layout.Comment >= 0,
+		HasResponse: response >= 0,
+	}
+	if res.HasReviewer {
+		res.Reviewer = headers[reviewer]
+	}
+	if res.HasComment {
+		res.Comment = headers[layout.Comment]
+	}
+	if res.HasResponse {
+		res.Response = headers[response]
+	}
+	for _, col := range layout.Details {
+		res.Details = append(res.Details, headers[col])
+	}
+	return res
+}
+
+func newComment(td *reader.TabularData, idx int, reviewer string, layout reader.Layout, response int, scheme *common.IDScheme) Comment {
+	cell := func(col int) Cell {
+		c := Cell{Header: td.Headers[col], Rich: td.RichCell(idx, col)}
+		if rec := td.Records[idx]; col < len(rec) {
+			c.Text = rec[col]
+		}
+		return c
+	}
+
+	res := Comment{
+		ID:       cell(layout.ID),
+		Reviewer: reviewer,
+		Extra:    make(map[string]Cell),
+		Index:    idx,
+		Row:      td.Row(idx),
+	}
+	_, number, _ := scheme.Parse(res.ID.Text)
+	res.Number = cmp.Or(number, strings.TrimSpace(res.ID.Text))
+	if layout.Comment >= 0 {
+		res.Comment = cell(layout.Comment)
+	}
+	for _, col := range layout.Details {
+		if col == response {
+			res.Response = cell(col)
+		} else {
+			res.Extra[td.Headers[col]] = cell(col)
+		}
+	}
+	return res
+}
+
+// Details returns the cells of the response and the extra columns of the comment in the order of the columns.
+func (r Rejoinder) Details(c Comment) []Cell {
+	res := make([]Cell, 0, len(r.Columns.Details))
+	for _, h := range r.Columns.Details {
+		if r.Columns.HasResponse && h == r.Columns.Response {
+			res = append(res, c.Response)
+		} else {
+			res = append(res, c.Extra[h])
+		}
+	}
+	return res
+}
+
+// Fields returns the cell of the comment, if there is a comment column, followed by the details, see Details.
+func (r Rejoinder) Fields(c Comment) []Cell {
+	if !r.Columns.HasComment {
+		return r.Details(c)
+	}
+	return append([]Cell{c.Comment}, r.Details(c)...)
+}
+
+// Comments returns the comments of all reviewers in the order of the tabular data.
+func (r Rejoinder) Comments() []Comment {
+	var res []Comment
+	for _, rev := range r.Reviewers {
+		res = append(res, rev.Comments...)
+	}
+	slices.SortFunc(res, func(a, b Comment) int { return cmp.Compare(a.Index, b.Index) })
+	return res
+}
+
+// ColorID returns the color identifier of the reviewer with the given ID, see Reviewer.
+func (r Rejoinder) ColorID(reviewer string) string {
+	for _, rev := range r.Reviewers {
+		if rev.ID == reviewer {
+			return rev.ColorID
+		}
+	}
+	return ""
+}
+
+// ReviewerIDs returns the IDs of the reviewers in their order.
+func (r Rejoinder) ReviewerIDs() []string {
+	res := make([]string, len(r.Reviewers))
+	for i, rev := range r.Reviewers {
+		res[i] = rev.ID
+	}
+	return res
+}
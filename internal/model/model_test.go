@@ -0,0 +1,131 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/andreas-bauer/rejoinderoo/internal/reader"
+	"github.com/andreas-bauer/rejoinderoo/internal/templates/common"
+)
+
+func TestNew(t *testing.T) {
+	td := &reader.TabularData{
+		Headers: []string{"ID", "Comment", "Response", "Action"},
+		Records: [][]string{
+			{"Rev2.1", "c1", "r1", "a1"},
+			{"Rev1.1", "c2"},
+			{},
+			{"Rev2.2", "c3", "r3", "a3"},
+		},
+		Rows: []int{2, 3, 5, 6},
+	}
+	opts := common.Options{Meta: common.Metadata{Title: "Paper"}, Colors: map[string]common.Color{"Rev1": "#FF0000"}}
+
+	rj := New(td, opts)
+	if rj.Meta.Title != "Paper" {
+		t.Errorf("Meta.Title = %q; want %q", rj.Meta.Title, "Paper")
+	}
+	wantCols := Columns{ID: "ID", Comment: "Comment", HasComment: true, Response: "Response", HasResponse: true, Details: []string{"Response", "Action"}}
+	if !reflect.DeepEqual(rj.Columns, wantCols) {
+		t.Errorf("Columns = %+v; want %+v", rj.Columns, wantCols)
+	}
+	if got, want := rj.ReviewerIDs(), []string{"Rev2", "Rev1"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("ReviewerIDs() = %q; want %q", got, want)
+	}
+	if rev := rj.Reviewers[1]; rev.Name != "Reviewer 1" || rev.Color != "#FF0000" {
+		t.Errorf("Reviewers[1] = %+v; want Reviewer 1 in #FF0000", rev)
+	}
+
+	want := Comment{
+		ID:       Cell{Header: "ID", Text: "Rev2.2"},
+		Number:   "2",
+		Reviewer: "Rev2",
+		Comment:  Cell{Header: "Comment", Text: "c3"},
+		Response: Cell{Header: "Response", Text: "r3"},
+		Extra:    map[string]Cell{"Action": {Header: "Action", Text: "a3"}},
+		Index:    3,
+		Row:      6,
+	}
+	if got := rj.Reviewers[0].Comments[1]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Reviewers[0].Comments[1] = %+v; want %+v", got, want)
+	}
+
+	short := rj.Reviewers[1].Comments[0]
+	wantFields := []Cell{{Header: "Comment", Text: "c2"}, {Header: "Response"}, {Header: "Action"}}
+	if got := rj.Fields(short); !reflect.DeepEqual(got, wantFields) {
+		t.Errorf("Fields() = %+v; want %+v", got, wantFields)
+	}
+
+	var ids []string
+	for _, c := range rj.Comments() {
+		ids = append(ids, c.ID.Text)
+	}
+	if want := []string{"Rev2.1", "Rev1.1", "Rev2.2"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Comments() IDs = %q; want %q", ids, want)
+	}
+}
+
+func TestNew_Roles(t *testing.T) {
+	td := &reader.TabularData{
+		Headers: []string{"No", "Reviewer", "Where", "Answer", "Status", "Remark"},
+		Records: [][]string{{"1", "Editor", "Sec. 1", "r1", "done", "c1"}},
+		Rich:    [][]reader.RichText{{nil, nil, nil, {{Text: "r1", Bold: true}}}},
+		Roles:   []reader.Role{reader.IDRole, reader.ReviewerRole, reader.LocationRole, reader.NoRole, reader.HiddenRole, reader.CommentRole},
+	}
+
+	rj := New(td, common.Options{})
+	wantCols := Columns{ID: "No", Reviewer: "Reviewer", HasReviewer: true, Comment: "Remark", HasComment: true, Response: "Answer", HasResponse: true, Details: []string{"Where", "Answer"}}
+	if !reflect.DeepEqual(rj.Columns, wantCols) {
+		t.Errorf("Columns = %+v; want %+v", rj.Columns, wantCols)
+	}
+	if len(rj.Reviewers) != 1 || rj.Reviewers[0].ID != "Editor" {
+		t.Fatalf("Reviewers = %+v; want Editor from the reviewer column", rj.Reviewers)
+	}
+
+	c := rj.Reviewers[0].Comments[0]
+	if c.Number != "1" {
+		t.Errorf("Number = %q; want the ID %q", c.Number, "1")
+	}
+	if c.Response.Rich == nil {
+		t.Errorf("Response = %+v; want the formatting of the cell", c.Response)
+	}
+	// the details keep their order: the location precedes the response without a role
+	var headers []string
+	for _, cell := range rj.Details(c) {
+		headers = append(headers, cell.Header)
+	}
+	if want := []string{"Where", "Answer"}; !reflect.DeepEqual(headers, want) {
+		t.Errorf("Details() headers = %q; want %q", headers, want)
+	}
+}
+
+func TestNew_Empty(t *testing.T) {
+	opts := common.Options{Meta: common.Metadata{Title: "Paper"}}
+	for _, td := range []*reader.TabularData{{}, {Records: [][]string{{"R1.1", "c1"}}}} {
+		rj := New(td, opts)
+		if rj.Meta.Title != "Paper" || len(rj.Reviewers) != 0 || len(rj.Comments()) != 0 {
+			t.Errorf("New(%+v) = %+v; want no reviewers", td, rj)
+		}
+	}
+}
+
+func TestResponseColumn(t *testing.T) {
+	tests := []struct {
+		name  string
+		roles []reader.Role
+		want  int
+	}{
+		{"second column without a role", nil, 2},
+		{"response role", []reader.Role{reader.NoRole, reader.NoRole, reader.NoRole, reader.ResponseRole}, 3},
+		{"no-role column after an action", []reader.Role{reader.NoRole, reader.NoRole, reader.ActionRole, reader.NoRole}, 3},
+		{"only roles", []reader.Role{reader.IDRole, reader.CommentRole, reader.ActionRole, reader.HiddenRole}, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td := &reader.TabularData{Headers: []string{"A", "B", "C", "D"}, Roles: tt.roles}
+			if got := ResponseColumn(td); got != tt.want {
+				t.Errorf("ResponseColumn() = %d; want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package order
+
+import (
+	"strings"
+)
+
+// NaturalCompare compares two strings like strings.Compare, except that runs of digits
+// are compared by their numeric value, so that Rev1.9 comes before Rev1.10.
+// Letters are compared case-insensitively first.
+func NaturalCompare(a, b string) int {
+	x, y := a, b
+	for x != "" && y != "" {
+		xd, yd := isDigit(x[0]), isDigit(y[0])
+		var cx, cy string
+		if xd && yd {
+			cx, x = chunk(x, true)
+			cy, y = chunk(y, true)
+			if c := compareNumbers(cx, cy); c != 0 {
+				return c
+			}
+			continue
+		}
+		cx, x = chunk(x, xd)
+		cy, y = chunk(y, yd)
+		if c := strings.Compare(strings.ToLower(cx), strings.ToLower(cy)); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case x == "" && y != "":
+		return -1
+	case x != "" && y == "":
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// chunk splits the leading run of digits, or non-digits, from the text.
+func chunk(text string, digits bool) (string, string) {
+	i := 0
+	for i < len(text) && isDigit(text[i]) == digits {
+		i++
+	}
+	return text[:i], text[i:]
+}
+
+// compareNumbers compares two runs of digits by their value, regardless of their length.
+func compareNumbers(a, b string) int {
+	a = strings.TrimLeft(a, "0")
+	b = strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
@@ -0,0 +1,41 @@
+package order
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNaturalCompare(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"Rev1.9", "Rev1.10", -1},
+		{"Rev1.10", "Rev1.9", 1},
+		{"Rev2.1", "Rev10.1", -1},
+		{"Rev1.01", "Rev1.1", -1},
+		{"rev1", "Rev2", -1},
+		{"Rev1", "Rev1.1", -1},
+		{"R1", "Rev1", -1},
+		{"Rev1.1", "Rev1.1", 0},
+		{"", "a", -1},
+		{"9", "a", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			if got := NaturalCompare(tt.a, tt.b); got != tt.expected {
+				t.Errorf("NaturalCompare(%q, %q) = %d; want %d", tt.a, tt.b, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNaturalCompare_Sort(t *testing.T) {
+	ids := []string{"Rev1.10", "Rev2.1", "Rev1.2", "Rev1.1", "Rev10.1", "Rev1.9"}
+	slices.SortFunc(ids, NaturalCompare)
+	want := []string{"Rev1.1", "Rev1.2", "Rev1.9", "Rev1.10", "Rev2.1", "Rev10.1"}
+	if !slices.Equal(ids, want) {
+		t.Errorf("sorted = %v; want %v", ids, want)
+	}
+}
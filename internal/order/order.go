@@ -0,0 +1,221 @@
+package order
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/andreas-bauer/rejoinderoo/internal/reader"
+	"github.com/andreas-bauer/rejoinderoo/internal/templates/common"
+)
+
+// Mode selects how the responses are ordered.
+type Mode int
+
+const (
+	// Sheet keeps the order of the spreadsheet.
+	Sheet Mode = iota
+	// ID sorts the responses naturally by their ID, e.g., Rev1.10 after Rev1.9.
+	ID
+	// Reviewer groups the responses by reviewer, in the order in which the reviewers first appear,
+	// and sorts them by comment number.
+	Reviewer
+	// Column sorts the responses naturally by the values of a column, e.g., priority or status.
+	Column
+	// Reviewers groups the responses by reviewer in an explicit order, e.g., "Editor, R2, R1",
+	// and sorts them by comment number. Reviewers that are not listed follow in the order in which they appear.
+	Reviewers
+)
+
+var modeNames = map[Mode]string{
+	Sheet:     "sheet",
+	ID:        "id",
+	Reviewer:  "reviewer",
+	Column:    "column",
+	Reviewers: "reviewers",
+}
+
+// Modes returns the names of all modes.
+func Modes() []string {
+	return []string{modeNames[Sheet], modeNames[ID], modeNames[Reviewer], modeNames[Column], modeNames[Reviewers]}
+}
+
+// ParseMode returns the mode with the given name. An empty name selects the spreadsheet order.
+func ParseMode(name string) (Mode, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return Sheet, nil
+	}
+	for m, n := range modeNames {
+		if n == name {
+			return m, nil
+		}
+	}
+	return Sheet, fmt.Errorf("order %q is not supported, choose one of %v", name, Modes())
+}
+
+func (m Mode) String() string {
+	return modeNames[m]
+}
+
+// Order is a transformation that rearranges the records of tabular data.
+type Order struct {
+	Mode Mode
+	// Column is the header of the column to sort by in Column mode.
+	Column string
+	// Reviewers is the explicit order of the reviewers in Reviewers mode.
+	Reviewers []string
+}
+
+// Parse parses an order of the form "mode", "column=<header>", or "reviewers=<reviewer>, <reviewer>, ...",
+// e.g., "id" or "reviewers=Editor, R2, R1".
+func Parse(spec string) (Order, error) {
+	name, arg, hasArg := strings.Cut(spec, "=")
+	mode, err := ParseMode(name)
+	if err != nil {
+		return Order{}, err
+	}
+	o := Order{Mode: mode}
+	switch mode {
+	case Column:
+		o.Column = strings.TrimSpace(arg)
+		if o.Column == "" {
+			return Order{}, fmt.Errorf("order %q requires a column, e.g., column=Status", spec)
+		}
+	case Reviewers:
+		o.Reviewers = splitList(arg)
+		if len(o.Reviewers) == 0 {
+			return Order{}, fmt.Errorf("order %q requires a list of reviewers, e.g., reviewers=Editor, R2, R1", spec)
+		}
+	default:
+		if hasArg {
+			return Order{}, fmt.Errorf("order %q does not take an argument", spec)
+		}
+	}
+	return o, nil
+}
+
+// String returns the order in the form accepted by Parse.
+func (o Order) String() string {
+	switch o.Mode {
+	case Column:
+		return o.Mode.String() + "=" + o.Column
+	case Reviewers:
+		return o.Mode.String() + "=" + strings.Join(o.Reviewers, ", ")
+	}
+	return o.Mode.String()
+}
+
+// Apply rearranges the records of the tabular data. The column with the given header holds the IDs,
+// which the ID scheme parses into reviewer and comment number. A column with the reviewer role
+// takes precedence over the reviewer of the ID. The sort is stable.
+func (o Order) Apply(td *reader.TabularData, idHeader string, scheme *common.IDScheme) error {
+	if o.Mode == Sheet {
+		return nil
+	}
+	idCol := slices.Index(td.Headers, idHeader)
+	if idCol < 0 {
+		return fmt.Errorf("ID column %q not found", idHeader)
+	}
+	cell := func(rec []string, col int) string {
+		if col >= 0 && col < len(rec) {
+			return rec[col]
+		}
+		return ""
+	}
+
+	var compare func(a, b []string) int
+	switch o.Mode {
+	case ID:
+		compare = func(a, b []string) int {
+			return NaturalCompare(cell(a, idCol), cell(b, idCol))
+		}
+	case Column:
+		col := slices.Index(td.Headers, o.Column)
+		if col < 0 {
+			return fmt.Errorf("column %q not found", o.Column)
+		}
+		compare = func(a, b []string) int {
+			x, y := cell(a, col), cell(b, col)
+			// empty values last
+			switch {
+			case x == "" && y != "":
+				return 1
+			case x != "" && y == "":
+				return -1
+			}
+			return NaturalCompare(x, y)
+		}
+	case Reviewer, Reviewers:
+		reviewerCol := td.Column(reader.ReviewerRole)
+		parse := func(rec []string) (reviewer, comment string) {
+			id := cell(rec, idCol)
+			reviewer, comment, _ = scheme.Parse(id)
+			if r := strings.TrimSpace(cell(rec, reviewerCol)); r != "" {
+				// the ID may be the comment number only
+				reviewer, comment = r, cmp.Or(comment, id)
+			}
+			return reviewer, comment
+		}
+		var reviewers []string
+		for _, rec := range td.Records {
+			if r, _ := parse(rec); !slices.Contains(reviewers, r) {
+				reviewers = append(reviewers, r)
+			}
+		}
+		rank := o.reviewerRanks(reviewers)
+		compare = func(a, b []string) int {
+			ra, ca := parse(a)
+			rb, cb := parse(b)
+			if c := rank[ra] - rank[rb]; c != 0 {
+				return c
+			}
+			return NaturalCompare(ca, cb)
+		}
+	}
+
+	idx := make([]int, len(td.Records))
+	for i := range idx {
+		idx[i] = i
+	}
+	slices.SortStableFunc(idx, func(i, j int) int {
+		return compare(td.Records[i], td.Records[j])
+	})
+	td.Reorder(idx)
+	return nil
+}
+
+// reviewerRanks returns the position of each reviewer: the listed reviewers first,
+// the others in the given order, which is the order in which they appear.
+func (o Order) reviewerRanks(reviewers []string) map[string]int {
+	rank := make(map[string]int, len(reviewers))
+	for _, want := range o.Reviewers {
+		for _, r := range reviewers {
+			if _, ok := rank[r]; !ok && matchReviewer(r, want) {
+				rank[r] = len(rank)
+			}
+		}
+	}
+	for _, r := range reviewers {
+		if _, ok := rank[r]; !ok {
+			rank[r] = len(rank)
+		}
+	}
+	return rank
+}
+
+// matchReviewer reports whether the reviewer ID or its section heading, e.g., "Editor" for ED, is the given name.
+func matchReviewer(reviewer, name string) bool {
+	return strings.EqualFold(reviewer, name) || strings.EqualFold(common.ReviewerName(reviewer), name)
+}
+
+func splitList(list string) []string {
+	var res []string
+	for item := range strings.SplitSeq(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			res = append(res, item)
+		}
+	}
+	return res
+}
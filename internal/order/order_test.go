@@ -0,0 +1,110 @@
+package order
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/andreas-bauer/rejoinderoo/internal/reader"
+	"github.com/andreas-bauer/rejoinderoo/internal/templates/common"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected Order
+		wantErr  bool
+	}{
+		{"", Order{Mode: Sheet}, false},
+		{"ID", Order{Mode: ID}, false},
+		{"reviewer", Order{Mode: Reviewer}, false},
+		{"column=Status", Order{Mode: Column, Column: "Status"}, false},
+		{"reviewers=Editor, R2,R1", Order{Mode: Reviewers, Reviewers: []string{"Editor", "R2", "R1"}}, false},
+		{"column", Order{}, true},
+		{"reviewers= , ", Order{}, true},
+		{"id=Rev1", Order{}, true},
+		{"random", Order{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := Parse(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v; wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Parse(%q) = %+v; want %+v", tt.spec, got, tt.expected)
+			}
+			if err == nil {
+				if again, _ := Parse(got.String()); !reflect.DeepEqual(again, got) {
+					t.Errorf("Parse(%q) = %+v; want %+v", got.String(), again, got)
+				}
+			}
+		})
+	}
+}
+
+func TestOrder_Apply(t *testing.T) {
+	newTable := func() *reader.TabularData {
+		return &reader.TabularData{
+			Headers: []string{"Status", "ID", "Comment"},
+			Records: [][]string{
+				{"open", "Rev1.10", "a"},
+				{"", "Rev2.1", "b"},
+				{"done", "ED.1", "c"},
+				{"open", "Rev1.9", "d"},
+				{"done", "Rev2.2", "e"},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		order    Order
+		expected []string
+	}{
+		{"sheet", Order{Mode: Sheet}, []string{"Rev1.10", "Rev2.1", "ED.1", "Rev1.9", "Rev2.2"}},
+		{"id", Order{Mode: ID}, []string{"ED.1", "Rev1.9", "Rev1.10", "Rev2.1", "Rev2.2"}},
+		{"reviewer", Order{Mode: Reviewer}, []string{"Rev1.9", "Rev1.10", "Rev2.1", "Rev2.2", "ED.1"}},
+		{"column", Order{Mode: Column, Column: "Status"}, []string{"ED.1", "Rev2.2", "Rev1.10", "Rev1.9", "Rev2.1"}},
+		{"reviewers", Order{Mode: Reviewers, Reviewers: []string{"Editor", "Rev2"}}, []string{"ED.1", "Rev2.1", "Rev2.2", "Rev1.9", "Rev1.10"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td := newTable()
+			if err := tt.order.Apply(td, "ID", common.DefaultIDScheme); err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			var got []string
+			for _, rec := range td.Records {
+				got = append(got, rec[1])
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Apply() IDs = %v; want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestOrder_ApplyKeepsRows(t *testing.T) {
+	td := &reader.TabularData{
+		Headers: []string{"ID"},
+		Records: [][]string{{"Rev1.2"}, {"Rev1.1"}},
+	}
+	if err := (Order{Mode: ID}).Apply(td, "ID", nil); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if got := []int{td.Row(0), td.Row(1)}; !reflect.DeepEqual(got, []int{3, 2}) {
+		t.Errorf("Apply() rows = %v; want [3 2]", got)
+	}
+}
+
+func TestOrder_ApplyMissingColumn(t *testing.T) {
+	td := &reader.TabularData{Headers: []string{"ID"}, Records: [][]string{{"Rev1.1"}}}
+	if err := (Order{Mode: Column, Column: "Priority"}).Apply(td, "ID", nil); err == nil {
+		t.Errorf("Apply() expected error for missing column")
+	}
+	if err := (Order{Mode: ID}).Apply(td, "Key", nil); err == nil {
+		t.Errorf("Apply() expected error for missing ID column")
+	}
+}
@@ -2,6 +2,7 @@ package reader
 
 import (
 	"encoding/csv"
+	"errors"
 	"io"
 )
 
@@ -10,9 +11,19 @@ type CSVReader struct{}
 func (r CSVReader) Read(file io.Reader) (*TabularData, error) {
 	reader := csv.NewReader(file)
 
-	rows, err := reader.ReadAll()
-	if err != nil {
-		return nil, err
+	var rows [][]string
+	var lines []int
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, row)
+		lines = append(lines, line)
 	}
 
 	if len(rows) == 0 {
@@ -25,5 +36,6 @@ func (r CSVReader) Read(file io.Reader) (*TabularData, error) {
 	return &TabularData{
 		Headers: rows[0],
 		Records: rows[1:],
+		Rows:    lines[1:],
 	}, nil
 }
@@ -0,0 +1,45 @@
+package reader
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCSVReader_Read(t *testing.T) {
+	input := "ID,Comment,Response\n" +
+		"Rev1.1,\"multi\nline\",Done\n" +
+		"\n" +
+		"Rev1.2,Second,Done\n"
+
+	td, err := CSVReader{}.Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	wantHeaders := []string{"ID", "Comment", "Response"}
+	if !reflect.DeepEqual(td.Headers, wantHeaders) {
+		t.Errorf("Headers = %v, want %v", td.Headers, wantHeaders)
+	}
+	wantRecords := [][]string{
+		{"Rev1.1", "multi\nline", "Done"},
+		{"Rev1.2", "Second", "Done"},
+	}
+	if !reflect.DeepEqual(td.Records, wantRecords) {
+		t.Errorf("Records = %v, want %v", td.Records, wantRecords)
+	}
+	wantRows := []int{2, 5}
+	if !reflect.DeepEqual(td.Rows, wantRows) {
+		t.Errorf("Rows = %v, want %v", td.Rows, wantRows)
+	}
+}
+
+func TestCSVReader_ReadEmpty(t *testing.T) {
+	td, err := CSVReader{}.Read(strings.NewReader(""))
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(td.Headers) != 0 || len(td.Records) != 0 {
+		t.Errorf("Read() = %+v, want empty tabular data", td)
+	}
+}
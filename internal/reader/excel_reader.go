@@ -1,20 +1,68 @@
 package reader
 
 import (
+	"cmp"
+	"fmt"
 	"io"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/xuri/excelize/v2"
 )
 
-type ExcelReader struct{}
+// ExcelReader reads a single sheet of an Excel workbook.
+// Sheet selects the sheet by name or 1-based index; the first sheet is used if empty.
+type ExcelReader struct {
+	Sheet string
+}
+
+func (r *ExcelReader) Read(file io.Reader) (*TabularData, error) {
+	f, err := excelize.OpenReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sheet, err := resolveSheet(f.GetSheetList(), r.Sheet)
+	if err != nil {
+		return nil, err
+	}
+	return readSheet(f, sheet)
+}
 
-func (r ExcelReader) Read(file io.Reader) (*TabularData, error) {
+// ReadSheets reads the given sheets, selected by name or 1-based index, in the given order.
+// All sheets are read in workbook order if no sheets are given.
+func (r *ExcelReader) ReadSheets(file io.Reader, sheets []string) (*Workbook, error) {
 	f, err := excelize.OpenReader(file)
 	if err != nil {
 		return nil, err
 	}
+	defer f.Close()
 
-	sheet := f.GetSheetName(0)
+	names := f.GetSheetList()
+	if len(sheets) > 0 {
+		names = make([]string, len(sheets))
+		for i, s := range sheets {
+			if names[i], err = resolveSheet(f.GetSheetList(), s); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	wb := &Workbook{}
+	for _, name := range names {
+		td, err := readSheet(f, name)
+		if err != nil {
+			return nil, fmt.Errorf("sheet %q: %w", name, err)
+		}
+		wb.Sheets = append(wb.Sheets, Sheet{Name: name, Data: td})
+	}
+	return wb, nil
+}
+
+// readSheet reads the sheet with the given name; the first row holds the headers.
+func readSheet(f *excelize.File, sheet string) (*TabularData, error) {
 	rows, err := f.GetRows(sheet)
 	if err != nil {
 		return nil, err
@@ -27,8 +75,148 @@ func (r ExcelReader) Read(file io.Reader) (*TabularData, error) {
 		}, nil
 	}
 
+	lines := make([]int, len(rows)-1)
+	for i := range lines {
+		lines[i] = i + 2 // 1-based and skip headers
+	}
+
+	rich, err := readRichText(f, sheet, rows[1:])
+	if err != nil {
+		return nil, err
+	}
+
 	return &TabularData{
 		Headers: rows[0],
 		Records: rows[1:], // skip headers
+		Rows:    lines,
+		Rich:    rich,
 	}, nil
 }
+
+// readRichText reads the formatting of the given records, which start in the second row.
+// The formatting is taken from rich-text runs, the cell style, and cell hyperlinks.
+// It returns nil if no cell is formatted.
+func readRichText(f *excelize.File, sheet string, records [][]string) ([][]RichText, error) {
+	var res [][]RichText
+	for i, rec := range records {
+		for j, value := range rec {
+			if value == "" {
+				continue
+			}
+			cell, err := excelize.CoordinatesToCellName(j+1, i+2)
+			if err != nil {
+				return nil, err
+			}
+			rt, err := cellRichText(f, sheet, cell, value)
+			if err != nil {
+				return nil, err
+			}
+			if !rt.Formatted() {
+				continue
+			}
+
+			if res == nil {
+				res = make([][]RichText, len(records))
+			}
+			if res[i] == nil {
+				res[i] = make([]RichText, len(rec))
+			}
+			res[i][j] = rt
+		}
+	}
+	return res, nil
+}
+
+func cellRichText(f *excelize.File, sheet, cell, value string) (RichText, error) {
+	runs, err := f.GetCellRichText(sheet, cell)
+	if err != nil {
+		return nil, err
+	}
+
+	// runs without font inherit the formatting of the whole cell
+	font, err := cellFont(f, sheet, cell)
+	if err != nil {
+		return nil, err
+	}
+
+	var rt RichText
+	for _, r := range runs {
+		rt = append(rt, newRun(r.Text, cmp.Or(r.Font, font)))
+	}
+	if len(rt) == 0 {
+		rt = RichText{newRun(value, font)}
+	}
+
+	ok, target, err := f.GetCellHyperLink(sheet, cell)
+	if err != nil {
+		return nil, err
+	}
+	if ok && isURL(target) {
+		for i := range rt {
+			rt[i].Link = target
+		}
+	}
+	return rt, nil
+}
+
+func cellFont(f *excelize.File, sheet, cell string) (*excelize.Font, error) {
+	idx, err := f.GetCellStyle(sheet, cell)
+	if err != nil || idx == 0 {
+		return nil, err
+	}
+	style, err := f.GetStyle(idx)
+	if err != nil {
+		return nil, err
+	}
+	return style.Font, nil
+}
+
+func newRun(text string, font *excelize.Font) Run {
+	r := Run{Text: text}
+	if font != nil {
+		r.Bold = font.Bold
+		r.Italic = font.Italic
+		r.Underline = font.Underline != "" && font.Underline != "none"
+	}
+	return r
+}
+
+// isURL reports whether the hyperlink target is an external URL
+// rather than a location in the workbook.
+func isURL(target string) bool {
+	return strings.Contains(target, "://") || strings.HasPrefix(target, "mailto:")
+}
+
+// Sheets returns the names of all sheets in the workbook.
+func (r *ExcelReader) Sheets(file io.Reader) ([]string, error) {
+	f, err := excelize.OpenReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return f.GetSheetList(), nil
+}
+
+// SelectSheet selects the sheet to read by name or 1-based index.
+func (r *ExcelReader) SelectSheet(sheet string) {
+	r.Sheet = sheet
+}
+
+// resolveSheet returns the name of the selected sheet. A sheet is selected
+// by its name or, if no sheet has that name, by its 1-based index.
+func resolveSheet(sheets []string, selected string) (string, error) {
+	if len(sheets) == 0 {
+		return "", fmt.Errorf("workbook does not contain any sheets")
+	}
+	if selected == "" {
+		return sheets[0], nil
+	}
+	if slices.Contains(sheets, selected) {
+		return selected, nil
+	}
+	if idx, err := strconv.Atoi(selected); err == nil && idx >= 1 && idx <= len(sheets) {
+		return sheets[idx-1], nil
+	}
+	return "", fmt.Errorf("sheet %q not found, available sheets are %q", selected, sheets)
+}
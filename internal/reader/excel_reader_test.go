@@ -0,0 +1,216 @@
+package reader
+
+import (
+	"bytes"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// newWorkbook creates an Excel workbook with the given sheets and rows.
+func newWorkbook(t *testing.T, sheets map[string][][]string, order []string) *bytes.Buffer {
+	t.Helper()
+	f := excelize.NewFile()
+	defer f.Close()
+
+	for i, name := range order {
+		if i == 0 {
+			if err := f.SetSheetName("Sheet1", name); err != nil {
+				t.Fatal(err)
+			}
+		} else if _, err := f.NewSheet(name); err != nil {
+			t.Fatal(err)
+		}
+		for r, row := range sheets[name] {
+			cell, err := excelize.CoordinatesToCellName(1, r+1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := f.SetSheetRow(name, cell, &row); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf
+}
+
+func TestExcelReader_SheetSelection(t *testing.T) {
+	order := []string{"Round 1", "Round 2", "Notes"}
+	sheets := map[string][][]string{
+		"Round 1": {{"ID", "Comment"}, {"Rev1.1", "First round"}},
+		"Round 2": {{"ID", "Comment"}, {"Rev1.1", "Second round"}},
+		"Notes":   {{"Note"}, {"internal"}},
+	}
+	content := newWorkbook(t, sheets, order).Bytes()
+
+	tests := []struct {
+		name     string
+		sheet    string
+		expected string
+		wantErr  bool
+	}{
+		{"default is first sheet", "", "First round", false},
+		{"by name", "Round 2", "Second round", false},
+		{"by index", "2", "Second round", false},
+		{"unknown name", "Round 3", "", true},
+		{"index out of range", "4", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &ExcelReader{}
+			r.SelectSheet(tt.sheet)
+			td, err := r.Read(bytes.NewReader(content))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Read() expected error for sheet %q", tt.sheet)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			if td.Records[0][1] != tt.expected {
+				t.Errorf("Read() comment = %q, want %q", td.Records[0][1], tt.expected)
+			}
+		})
+	}
+}
+
+func TestExcelReader_Sheets(t *testing.T) {
+	order := []string{"Round 1", "Round 2", "Notes"}
+	content := newWorkbook(t, map[string][][]string{}, order)
+
+	got, err := (&ExcelReader{}).Sheets(content)
+	if err != nil {
+		t.Fatalf("Sheets() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, order) {
+		t.Errorf("Sheets() = %v, want %v", got, order)
+	}
+}
+
+func TestExcelReader_ReadSheets(t *testing.T) {
+	order := []string{"Round 1", "Round 2", "Notes"}
+	sheets := map[string][][]string{
+		"Round 1": {{"ID", "Comment"}, {"Rev1.1", "First round"}},
+		"Round 2": {{"ID", "Comment"}, {"Rev1.1", "Second round"}},
+		"Notes":   {{"Note"}, {"internal"}},
+	}
+	content := newWorkbook(t, sheets, order).Bytes()
+
+	tests := []struct {
+		name     string
+		sheets   []string
+		expected []string
+		wantErr  bool
+	}{
+		{"all sheets", nil, []string{"Round 1", "Round 2", "Notes"}, false},
+		{"by name and index", []string{"Round 1", "2"}, []string{"Round 1", "Round 2"}, false},
+		{"unknown sheet", []string{"Round 1", "Round 3"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wb, err := (&ExcelReader{}).ReadSheets(bytes.NewReader(content), tt.sheets)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ReadSheets() expected error for sheets %q", tt.sheets)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadSheets() error = %v", err)
+			}
+
+			var names []string
+			for _, s := range wb.Sheets {
+				names = append(names, s.Name)
+				want := sheets[s.Name][1:]
+				if !reflect.DeepEqual(s.Data.Records, want) {
+					t.Errorf("ReadSheets() sheet %q records = %v, want %v", s.Name, s.Data.Records, want)
+				}
+			}
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("ReadSheets() sheets = %v, want %v", names, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExcelReader_RichText(t *testing.T) {
+	f := excelize.NewFile()
+	defer f.Close()
+	sheet := "Sheet1"
+	rows := [][]string{
+		{"ID", "Comment", "Response"},
+		{"Rev1.1", "plain", ""},
+		{"Rev1.2", "bold cell", "see docs"},
+	}
+	for r, row := range rows {
+		if err := f.SetSheetRow(sheet, "A"+strconv.Itoa(r+1), &row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := f.SetCellRichText(sheet, "C2", []excelize.RichTextRun{
+		{Text: "We "},
+		{Text: "fixed", Font: &excelize.Font{Bold: true}},
+		{Text: " it", Font: &excelize.Font{Italic: true, Underline: "single"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	style, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SetCellStyle(sheet, "B3", "B3", style); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SetCellHyperLink(sheet, "C3", "https://example.com", "External"); err != nil {
+		t.Fatal(err)
+	}
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	td, err := (&ExcelReader{}).Read(buf)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		row, col int
+		expected RichText
+	}{
+		{"plain cell", 0, 1, nil},
+		{"rich-text runs", 0, 2, RichText{
+			{Text: "We "},
+			{Text: "fixed", Bold: true},
+			{Text: " it", Italic: true, Underline: true},
+		}},
+		{"cell style", 1, 1, RichText{{Text: "bold cell", Bold: true}}},
+		{"hyperlink", 1, 2, RichText{{Text: "see docs", Link: "https://example.com"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := td.RichCell(tt.row, tt.col)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("RichCell(%d, %d) = %+v, want %+v", tt.row, tt.col, got, tt.expected)
+			}
+		})
+	}
+	if td.Records[0][2] != "We fixed it" {
+		t.Errorf("Read() plain text = %q, want %q", td.Records[0][2], "We fixed it")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
@@ -12,10 +13,76 @@ type TabularReader interface {
 	Read(file io.Reader) (*TabularData, error)
 }
 
+// SheetReader is implemented by readers of formats with several sheets, e.g., Excel workbooks.
+type SheetReader interface {
+	TabularReader
+	// Sheets returns the names of all sheets.
+	Sheets(file io.Reader) ([]string, error)
+	// SelectSheet selects the sheet to read by name or 1-based index.
+	SelectSheet(sheet string)
+	// ReadSheets reads the given sheets, selected by name or 1-based index, or all sheets if none are given.
+	ReadSheets(file io.Reader, sheets []string) (*Workbook, error)
+}
+
+// Workbook holds several named tables, e.g., one sheet per review round,
+// in the order of the input file.
+type Workbook struct {
+	Sheets []Sheet
+}
+
+// Sheet is a named table of a workbook.
+type Sheet struct {
+	Name string
+	Data *TabularData
+}
+
+// Keep filters the headers and records of all sheets, see TabularData.Keep.
+func (wb *Workbook) Keep(headers []string) {
+	for _, s := range wb.Sheets {
+		s.Data.Keep(headers)
+	}
+}
+
+// MissingHeaders returns the missing headers of each sheet that lacks some of the given headers,
+// see TabularData.MissingHeaders.
+func (wb *Workbook) MissingHeaders(headers []string) map[string][]string {
+	res := make(map[string][]string)
+	for _, s := range wb.Sheets {
+		if missing := s.Data.MissingHeaders(headers); len(missing) > 0 {
+			res[s.Name] = missing
+		}
+	}
+	return res
+}
+
 // TabularData represents the structure of a spreadsheet file with headers and records.
+// Rows holds the 1-based row (or line) number in the input file of each record, if known.
+// Rich holds the formatted text of the cells parallel to Records, if the input format
+// supports formatting, e.g., Excel. Missing rows and cells have no formatting.
+// Roles holds the assigned role of each column parallel to Headers, see ColumnRoles.
 type TabularData struct {
 	Headers []string
 	Records [][]string
+	Rows    []int
+	Rich    [][]RichText
+	Roles   []Role
+}
+
+// Row returns the row number in the input file of the record with the given index.
+// If the row numbers are unknown, the row is derived from the index assuming a single header row.
+func (td *TabularData) Row(idx int) int {
+	if idx < len(td.Rows) {
+		return td.Rows[idx]
+	}
+	return idx + 2
+}
+
+// RichCell returns the formatted text of the given cell, or nil if the cell has no formatting.
+func (td *TabularData) RichCell(row, col int) RichText {
+	if row >= len(td.Rich) || col >= len(td.Rich[row]) {
+		return nil
+	}
+	return td.Rich[row][col]
 }
 
 // NewReader creates an appropriate TabularReader based on the file extension.
@@ -38,10 +105,27 @@ func SupportedFileExtensions() []string {
 	return []string{".csv", ".xlsx", ".xls"}
 }
 
+// MissingHeaders returns all given headers that do not exist in the tabular data.
+func (td *TabularData) MissingHeaders(headers []string) []string {
+	var missing []string
+	for _, h := range headers {
+		if !slices.Contains(td.Headers, h) {
+			missing = append(missing, h)
+		}
+	}
+	return missing
+}
+
 // Keep filters the headers and records by removing all headers and the corresponding records
 // that are not in the given list of headers to keep.
-// It changes the order of the headers and records to match the order of headers to keep.
+// It changes the order of the headers and records to match the order of headers to keep,
+// except for a column with the ID role, which is moved to the front.
 func (td *TabularData) Keep(headers []string) {
+	if id := td.IDHeader(headers); len(headers) > 0 && headers[0] != id {
+		rest := slices.DeleteFunc(slices.Clone(headers), func(h string) bool { return h == id })
+		headers = append([]string{id}, rest...)
+	}
+
 	// Map header to its index in the original headers
 	headerIndex := make(map[string]int)
 	for i, h := range td.Headers {
@@ -57,6 +141,27 @@ func (td *TabularData) Keep(headers []string) {
 		}
 	}
 
+	if td.Roles != nil {
+		newRoles := make([]Role, len(indicesToKeep))
+		for j, idx := range indicesToKeep {
+			if idx < len(td.Roles) {
+				newRoles[j] = td.Roles[idx]
+			}
+		}
+		td.Roles = newRoles
+	}
+
+	if td.Rich != nil {
+		newRich := make([][]RichText, len(td.Rich))
+		for i := range td.Rich {
+			newRich[i] = make([]RichText, len(indicesToKeep))
+			for j, idx := range indicesToKeep {
+				newRich[i][j] = td.RichCell(i, idx)
+			}
+		}
+		td.Rich = newRich
+	}
+
 	newRecords := make([][]string, len(td.Records))
 	for i, record := range td.Records {
 		newRecord := make([]string, 0, len(indicesToKeep))
@@ -73,3 +178,24 @@ func (td *TabularData) Keep(headers []string) {
 	td.Headers = newHeaders
 	td.Records = newRecords
 }
+
+// Reorder rearranges the records, so that the i-th record is the former record order[i].
+// Row numbers and formatting move with their records. Records not in order are removed.
+func (td *TabularData) Reorder(order []int) {
+	records := make([][]string, len(order))
+	rows := make([]int, len(order))
+	var rich [][]RichText
+	if td.Rich != nil {
+		rich = make([][]RichText, len(order))
+	}
+	for i, idx := range order {
+		records[i] = td.Records[idx]
+		rows[i] = td.Row(idx)
+		if rich != nil && idx < len(td.Rich) {
+			rich[i] = td.Rich[idx]
+		}
+	}
+	td.Records = records
+	td.Rows = rows
+	td.Rich = rich
+}
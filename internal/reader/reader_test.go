@@ -143,3 +143,98 @@ func TestTabularData_Keep(t *testing.T) {
 		})
 	}
 }
+
+func TestTabularData_MissingHeaders(t *testing.T) {
+	td := &TabularData{
+		Headers: []string{"ID", "Comment", "Response"},
+	}
+
+	tests := []struct {
+		name     string
+		headers  []string
+		expected []string
+	}{
+		{"all exist", []string{"ID", "Response"}, nil},
+		{"none given", []string{}, nil},
+		{"some missing", []string{"ID", "Action", "Where"}, []string{"Action", "Where"}},
+		{"case-sensitive", []string{"id"}, []string{"id"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := td.MissingHeaders(tt.headers)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("MissingHeaders(%v) = %v, want %v", tt.headers, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTabularData_Row(t *testing.T) {
+	td := &TabularData{Rows: []int{2, 5}}
+	if got := td.Row(1); got != 5 {
+		t.Errorf("Row(1) = %d, want 5", got)
+	}
+	if got := td.Row(2); got != 4 {
+		t.Errorf("Row(2) without known row = %d, want 4", got)
+	}
+}
+
+func TestWorkbook_MissingHeaders(t *testing.T) {
+	wb := &Workbook{Sheets: []Sheet{
+		{Name: "Round 1", Data: &TabularData{Headers: []string{"ID", "Comment", "Response"}}},
+		{Name: "Round 2", Data: &TabularData{Headers: []string{"ID", "Comment", "Response", "Action"}}},
+	}}
+
+	got := wb.MissingHeaders([]string{"ID", "Response", "Action"})
+	expected := map[string][]string{"Round 1": {"Action"}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("MissingHeaders() = %v, want %v", got, expected)
+	}
+}
+
+func TestTabularData_KeepRichText(t *testing.T) {
+	bold := RichText{{Text: "Response A", Bold: true}}
+	td := &TabularData{
+		Headers: []string{"ID", "Comment", "Response"},
+		Records: [][]string{
+			{"R1.1", "Comment A", "Response A"},
+			{"R1.2", "Comment B", "Response B"},
+		},
+		Rich: [][]RichText{{nil, nil, bold}},
+	}
+
+	td.Keep([]string{"Response", "ID"})
+
+	expected := [][]RichText{{bold, nil}}
+	if !reflect.DeepEqual(td.Rich, expected) {
+		t.Errorf("Rich = %v, want %v", td.Rich, expected)
+	}
+	if got := td.RichCell(1, 0); got != nil {
+		t.Errorf("RichCell(1, 0) = %v, want nil", got)
+	}
+}
+
+func TestTabularData_Reorder(t *testing.T) {
+	td := &TabularData{
+		Headers: []string{"ID", "Comment"},
+		Records: [][]string{{"Rev1.1", "a"}, {"Rev1.2", "b"}, {"Rev1.3", "c"}},
+		Rich:    [][]RichText{nil, {nil, {{Text: "b", Bold: true}}}},
+	}
+
+	td.Reorder([]int{1, 2, 0})
+
+	wantRecords := [][]string{{"Rev1.2", "b"}, {"Rev1.3", "c"}, {"Rev1.1", "a"}}
+	if !reflect.DeepEqual(td.Records, wantRecords) {
+		t.Errorf("Reorder() records = %v; want %v", td.Records, wantRecords)
+	}
+	if want := []int{3, 4, 2}; !reflect.DeepEqual(td.Rows, want) {
+		t.Errorf("Reorder() rows = %v; want %v", td.Rows, want)
+	}
+	if rt := td.RichCell(0, 1); rt == nil || !rt[0].Bold {
+		t.Errorf("Reorder() RichCell(0, 1) = %v; want bold text", rt)
+	}
+	if rt := td.RichCell(2, 1); rt != nil {
+		t.Errorf("Reorder() RichCell(2, 1) = %v; want nil", rt)
+	}
+}
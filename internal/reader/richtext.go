@@ -0,0 +1,40 @@
+package reader
+
+import "strings"
+
+// Run is a part of the text of a cell with uniform formatting.
+// Link holds the target URL if the run is a hyperlink.
+type Run struct {
+	Text      string
+	Bold      bool
+	Italic    bool
+	Underline bool
+	Link      string
+}
+
+// Formatted reports whether the run has any formatting.
+func (r Run) Formatted() bool {
+	return r.Bold || r.Italic || r.Underline || r.Link != ""
+}
+
+// RichText is the text of a cell as a sequence of formatted runs.
+type RichText []Run
+
+// String returns the plain text without formatting.
+func (rt RichText) String() string {
+	var sb strings.Builder
+	for _, r := range rt {
+		sb.WriteString(r.Text)
+	}
+	return sb.String()
+}
+
+// Formatted reports whether any run of the rich text has formatting.
+func (rt RichText) Formatted() bool {
+	for _, r := range rt {
+		if r.Formatted() {
+			return true
+		}
+	}
+	return false
+}
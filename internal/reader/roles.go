@@ -0,0 +1,209 @@
+package reader
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Role tells the templates how to render a column.
+type Role int
+
+const (
+	// NoRole marks a column without an assigned role. Such columns are rendered below the comment.
+	NoRole Role = iota
+	// IDRole marks the column with the comment IDs.
+	IDRole
+	// ReviewerRole marks a column with the reviewer of each comment, which takes precedence over the ID scheme.
+	ReviewerRole
+	// CommentRole marks the column with the reviewer's comment.
+	CommentRole
+	// ResponseRole marks the column with the authors' response.
+	ResponseRole
+	// ActionRole marks the column with the actions taken in the manuscript.
+	ActionRole
+	// LocationRole marks the column with the location of the changes, e.g., the section.
+	LocationRole
+	// HiddenRole marks a metadata column, e.g., the status, that is kept but not rendered.
+	HiddenRole
+)
+
+var roleNames = map[Role]string{
+	NoRole:       "",
+	IDRole:       "id",
+	ReviewerRole: "reviewer",
+	CommentRole:  "comment",
+	ResponseRole: "response",
+	ActionRole:   "action",
+	LocationRole: "location",
+	HiddenRole:   "hidden",
+}
+
+// RoleNames returns the names of all roles that can be assigned to columns.
+func RoleNames() []string {
+	return []string{
+		roleNames[IDRole], roleNames[ReviewerRole], roleNames[CommentRole], roleNames[ResponseRole],
+		roleNames[ActionRole], roleNames[LocationRole], roleNames[HiddenRole],
+	}
+}
+
+// ParseRole returns the role with the given name. An empty name selects no role.
+func ParseRole(name string) (Role, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for r, n := range roleNames {
+		if n == name {
+			return r, nil
+		}
+	}
+	return NoRole, fmt.Errorf("role %q is not supported, choose one of %v", name, RoleNames())
+}
+
+func (r Role) String() string {
+	return roleNames[r]
+}
+
+// unique reports whether the role can be assigned to one column only.
+func (r Role) unique() bool {
+	return r != NoRole && r != HiddenRole
+}
+
+// ParseRoles parses a comma-separated list of column roles of the form "<header>=<role>",
+// e.g., "No=id, Remark=comment, Status=hidden".
+func ParseRoles(spec string) (map[string]Role, error) {
+	roles := make(map[string]Role)
+	for pair := range strings.SplitSeq(spec, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		header, name, ok := strings.Cut(pair, "=")
+		header = strings.TrimSpace(header)
+		if !ok || header == "" {
+			return nil, fmt.Errorf("column role %q must have the form <column>=<role>", strings.TrimSpace(pair))
+		}
+		role, err := ParseRole(name)
+		if err != nil {
+			return nil, fmt.Errorf("column %q: %w", header, err)
+		}
+		roles[header] = role
+	}
+	return roles, nil
+}
+
+// SetRoles assigns the roles to the columns with the given headers. Other columns have no role.
+// Each role except hidden can be assigned to one column only.
+func (td *TabularData) SetRoles(roles map[string]Role) error {
+	if missing := td.MissingHeaders(sortedKeys(roles)); len(missing) > 0 {
+		return fmt.Errorf("column(s) %q with a role not found", missing)
+	}
+	res := make([]Role, len(td.Headers))
+	assigned := make(map[Role]string)
+	for i, h := range td.Headers {
+		r := roles[h]
+		if prev, ok := assigned[r]; ok && r.unique() {
+			return fmt.Errorf("role %s is assigned to the columns %q and %q", r, prev, h)
+		}
+		assigned[r] = h
+		res[i] = r
+	}
+	td.Roles = res
+	return nil
+}
+
+// SetRoles assigns the roles to the columns of all sheets, see TabularData.SetRoles.
+func (wb *Workbook) SetRoles(roles map[string]Role) error {
+	for _, s := range wb.Sheets {
+		if err := s.Data.SetRoles(roles); err != nil {
+			return fmt.Errorf("sheet %q: %w", s.Name, err)
+		}
+	}
+	return nil
+}
+
+// ColumnRoles returns the role of each column. Without an assigned ID column,
+// the first column without a role holds the IDs; without an assigned comment column,
+// the first other column without a role holds the comments. These defaults do not change
+// when Keep moves the ID column to the front.
+func (td *TabularData) ColumnRoles() []Role {
+	res := make([]Role, len(td.Headers))
+	copy(res, td.Roles)
+	if len(res) == 0 {
+		return res
+	}
+	id := slices.Index(res, IDRole)
+	if id < 0 {
+		// every response needs an ID, even if all columns have other roles
+		id = max(slices.Index(res, NoRole), 0)
+		res[id] = IDRole
+	}
+	if !slices.Contains(res, CommentRole) {
+		if i := slices.Index(res, NoRole); i >= 0 {
+			res[i] = CommentRole
+		}
+	}
+	return res
+}
+
+// Column returns the index of the column with the given role, or -1 if there is none.
+func (td *TabularData) Column(role Role) int {
+	return slices.Index(td.ColumnRoles(), role)
+}
+
+// IDHeader returns the header of the ID column among the given headers, see ColumnRoles.
+func (td *TabularData) IDHeader(headers []string) string {
+	kept := &TabularData{Headers: headers, Roles: make([]Role, len(headers))}
+	for i, h := range headers {
+		if j := slices.Index(td.Headers, h); j >= 0 && j < len(td.Roles) {
+			kept.Roles[i] = td.Roles[j]
+		}
+	}
+	if id := kept.Column(IDRole); id >= 0 {
+		return headers[id]
+	}
+	return ""
+}
+
+// Layout holds the columns of a response box by role.
+type Layout struct {
+	// ID is the column of the IDs.
+	ID int
+	// Comment is the column of the comments, or -1 if there is none.
+	Comment int
+	// Details are the columns below the comment: the response, action, and location,
+	// followed by the columns without a role in their order.
+	Details []int
+}
+
+// detailRank orders the columns below the comment.
+var detailRank = map[Role]int{ResponseRole: 0, ActionRole: 1, LocationRole: 2, NoRole: 3}
+
+// Layout returns the columns of a response box by role. Reviewer and hidden columns are not part of it.
+func (td *TabularData) Layout() Layout {
+	roles := td.ColumnRoles()
+	l := Layout{ID: slices.Index(roles, IDRole), Comment: slices.Index(roles, CommentRole)}
+	for i, r := range roles {
+		if _, ok := detailRank[r]; ok {
+			l.Details = append(l.Details, i)
+		}
+	}
+	slices.SortStableFunc(l.Details, func(a, b int) int {
+		return detailRank[roles[a]] - detailRank[roles[b]]
+	})
+	return l
+}
+
+// Fields returns the comment column, if any, followed by the detail columns.
+func (l Layout) Fields() []int {
+	if l.Comment < 0 {
+		return slices.Clone(l.Details)
+	}
+	return append([]int{l.Comment}, l.Details...)
+}
+
+func sortedKeys(m map[string]Role) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
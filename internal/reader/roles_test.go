@@ -0,0 +1,116 @@
+package reader
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRoles(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected map[string]Role
+		wantErr  bool
+	}{
+		{"", map[string]Role{}, false},
+		{"No=id, Remark=Comment,Status=hidden,Owner=hidden", map[string]Role{
+			"No": IDRole, "Remark": CommentRole, "Status": HiddenRole, "Owner": HiddenRole,
+		}, false},
+		{"Where=", map[string]Role{"Where": NoRole}, false},
+		{"Remark", nil, true},
+		{"=comment", nil, true},
+		{"Status=secret", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseRoles(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRoles(%q) error = %v; wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ParseRoles(%q) = %v; want %v", tt.spec, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTabularData_SetRoles(t *testing.T) {
+	td := &TabularData{Headers: []string{"Status", "Response", "No", "Remark", "Owner"}}
+	err := td.SetRoles(map[string]Role{"No": IDRole, "Remark": CommentRole, "Status": HiddenRole, "Owner": HiddenRole})
+	if err != nil {
+		t.Fatalf("SetRoles() error = %v", err)
+	}
+	want := []Role{HiddenRole, NoRole, IDRole, CommentRole, HiddenRole}
+	if !reflect.DeepEqual(td.Roles, want) {
+		t.Errorf("SetRoles() roles = %v; want %v", td.Roles, want)
+	}
+
+	if err := td.SetRoles(map[string]Role{"No": IDRole, "Remark": IDRole}); err == nil {
+		t.Errorf("SetRoles() expected error for two ID columns")
+	}
+	if err := td.SetRoles(map[string]Role{"Priority": HiddenRole}); err == nil {
+		t.Errorf("SetRoles() expected error for missing column")
+	}
+}
+
+func TestTabularData_ColumnRoles(t *testing.T) {
+	tests := []struct {
+		name     string
+		roles    []Role
+		expected []Role
+	}{
+		{"positional", nil, []Role{IDRole, CommentRole, NoRole, NoRole}},
+		{"assigned", []Role{ResponseRole, CommentRole, IDRole, HiddenRole}, []Role{ResponseRole, CommentRole, IDRole, HiddenRole}},
+		{"assigned ID", []Role{NoRole, NoRole, IDRole, NoRole}, []Role{CommentRole, NoRole, IDRole, NoRole}},
+		{"reviewer first", []Role{ReviewerRole, NoRole, ResponseRole, NoRole}, []Role{ReviewerRole, IDRole, ResponseRole, CommentRole}},
+		{"no unassigned column", []Role{HiddenRole, ResponseRole, HiddenRole, HiddenRole}, []Role{IDRole, ResponseRole, HiddenRole, HiddenRole}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td := &TabularData{Headers: []string{"A", "B", "C", "D"}, Roles: tt.roles}
+			if got := td.ColumnRoles(); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ColumnRoles() = %v; want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTabularData_Layout(t *testing.T) {
+	td := &TabularData{
+		Headers: []string{"ID", "Where", "Response", "Status", "Comment", "Reviewer", "Action", "Notes"},
+		Roles:   []Role{IDRole, LocationRole, ResponseRole, HiddenRole, CommentRole, ReviewerRole, ActionRole, NoRole},
+	}
+	want := Layout{ID: 0, Comment: 4, Details: []int{2, 6, 1, 7}}
+	got := td.Layout()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Layout() = %+v; want %+v", got, want)
+	}
+	if fields := got.Fields(); !reflect.DeepEqual(fields, []int{4, 2, 6, 1, 7}) {
+		t.Errorf("Fields() = %v", fields)
+	}
+	if td.Column(ReviewerRole) != 5 || td.Column(LocationRole) != 1 {
+		t.Errorf("Column() = %d, %d; want 5, 1", td.Column(ReviewerRole), td.Column(LocationRole))
+	}
+}
+
+func TestTabularData_KeepRoles(t *testing.T) {
+	td := &TabularData{
+		Headers: []string{"Response", "Status", "No", "Comment"},
+		Records: [][]string{{"Fixed", "done", "Rev1.1", "Typo"}},
+		Roles:   []Role{ResponseRole, HiddenRole, IDRole, CommentRole},
+	}
+	if got := td.IDHeader([]string{"Response", "Comment", "No"}); got != "No" {
+		t.Errorf("IDHeader() = %q; want %q", got, "No")
+	}
+
+	td.Keep([]string{"Response", "Comment", "No", "Status"})
+	want := &TabularData{
+		Headers: []string{"No", "Response", "Comment", "Status"},
+		Records: [][]string{{"Rev1.1", "Fixed", "Typo", "done"}},
+		Roles:   []Role{IDRole, ResponseRole, CommentRole, HiddenRole},
+	}
+	if !reflect.DeepEqual(td, want) {
+		t.Errorf("Keep() = %+v; want %+v", td, want)
+	}
+}
@@ -1,22 +1,29 @@
 package server
 
 import (
+	"cmp"
+	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"html/template"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"net/url"
 	"slices"
 	"strings"
+	"time"
 
-	"github.com/andreas-bauer/rejoinderoo/internal/reader"
-	"github.com/andreas-bauer/rejoinderoo/internal/templates"
+	"github.com/andreas-bauer/rejoinderoo/rejoinder"
 )
 
 const (
 	maxUploadSize      = 10 << 20 // 10 MB
 	minSelectedColumns = 3
+	// customTemplateTimeout limits the validation of an uploaded custom template,
+	// e.g., of a recursive one.
+	customTemplateTimeout = 5 * time.Second
 )
 
 const (
@@ -26,10 +33,23 @@ const (
 	templateResult       = "result"
 )
 
+// previewExtension is the file extension of outputs that can be shown as live preview.
+const previewExtension = ".html"
+
 const (
 	formFieldFile        = "file"
 	formFieldGenTemplate = "gen-template"
+	formFieldCustomTmpl  = "template-file"
+	formFieldSheet       = "sheet"
+	formFieldIDScheme    = "id-scheme"
+	formFieldIDPattern   = "id-pattern"
+	formFieldPalette     = "palette"
+	formFieldOrder       = "order"
+	formFieldOrderColumn = "order-column"
+	formFieldOrderList   = "reviewer-order"
+	formFieldWhere       = "where"
 	headerPrefix         = "header-"
+	rolePrefix           = "role-"
 )
 
 // Handler struct for handling HTTP requests.
@@ -67,24 +87,48 @@ func (h *Handler) ColSelectForm(w http.ResponseWriter, r *http.Request) {
 	}
 	defer file.Close()
 
-	fileReader, err := reader.NewReader(handler.Filename)
+	sheet := r.FormValue(formFieldSheet)
+	doc, sheets, err := readTable(file, handler.Filename, sheet)
+	if err != nil && sheet != "" {
+		// the selected sheet may belong to a previously uploaded file
+		sheet = ""
+		if _, err = file.Seek(0, io.SeekStart); err == nil {
+			doc, sheets, err = readTable(file, handler.Filename, sheet)
+		}
+	}
 	if err != nil {
-		h.tmpl.ExecuteTemplate(w, templateError, "Unsupported file type: "+err.Error())
+		h.tmpl.ExecuteTemplate(w, templateError, err.Error())
 		return
 	}
 
-	tableData, err := fileReader.Read(file)
-	if err != nil {
-		h.tmpl.ExecuteTemplate(w, templateError, "Error reading file as table data: "+err.Error())
-		return
+	type idScheme struct{ Name, Example string }
+	var idSchemes []idScheme
+	for _, name := range rejoinder.IDSchemePresets() {
+		idSchemes = append(idSchemes, idScheme{Name: name, Example: rejoinder.IDSchemeExample(name)})
 	}
 
 	tmplArgs := struct {
-		Headers   []string
-		Templates []string
+		Headers      []string
+		Templates    []rejoinder.Template
+		Customizable []string
+		Palettes     []string
+		PaletteUsers []string
+		Sheets       []string
+		Sheet        string
+		IDSchemes    []idScheme
+		Orders       []string
+		Roles        []string
 	}{
-		Headers:   tableData.Headers,
-		Templates: templates.Available(),
+		Headers:      doc.Headers(),
+		Templates:    rejoinder.Templates(),
+		Customizable: rejoinder.TemplatesWith(rejoinder.OptionCustom),
+		Sheets:       sheets,
+		Sheet:        sheet,
+		IDSchemes:    idSchemes,
+		Palettes:     rejoinder.PaletteNames(),
+		PaletteUsers: rejoinder.TemplatesWith(rejoinder.OptionPalette),
+		Orders:       rejoinder.OrderModes(),
+		Roles:        rejoinder.RoleNames(),
 	}
 
 	if err := h.tmpl.ExecuteTemplate(w, templateSelectColumn, tmplArgs); err != nil {
@@ -113,46 +157,108 @@ func (h *Handler) Generate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	fileReader, err := reader.NewReader(handler.Filename)
+	doc, _, err := readTable(file, handler.Filename, r.FormValue(formFieldSheet))
 	if err != nil {
-		h.tmpl.ExecuteTemplate(w, templateError, "Unsupported file type: "+err.Error())
+		h.tmpl.ExecuteTemplate(w, templateError, err.Error())
 		return
 	}
 
-	tableData, err := fileReader.Read(file)
+	selectedHeaders = sortHeaders(selectedHeaders, doc.Headers())
+
+	scheme, err := rejoinder.ParseIDScheme(cmp.Or(r.FormValue(formFieldIDPattern), r.FormValue(formFieldIDScheme)))
+	if err != nil {
+		h.tmpl.ExecuteTemplate(w, templateError, err.Error())
+		return
+	}
+	roles, err := parseRoles(r.Form, selectedHeaders)
 	if err != nil {
-		h.tmpl.ExecuteTemplate(w, templateError, "Error reading file as table data: "+err.Error())
+		h.tmpl.ExecuteTemplate(w, templateError, err.Error())
+		return
+	}
+	ord, err := parseOrder(r.Form)
+	if err != nil {
+		h.tmpl.ExecuteTemplate(w, templateError, err.Error())
+		return
+	}
+	err = doc.Select(rejoinder.Selection{
+		Columns:  selectedHeaders,
+		Roles:    roles,
+		Where:    r.FormValue(formFieldWhere),
+		Order:    ord,
+		IDScheme: scheme,
+	})
+	if err != nil {
+		h.tmpl.ExecuteTemplate(w, templateError, err.Error())
 		return
 	}
 
-	selectedHeaders = sortHeaders(selectedHeaders, tableData.Headers)
-
-	tableData.Keep(selectedHeaders)
+	palette, err := rejoinder.ParsePalette(r.FormValue(formFieldPalette))
+	if err != nil {
+		h.tmpl.ExecuteTemplate(w, templateError, err.Error())
+		return
+	}
+	genTmpl, err := rejoinder.LookupTemplate(r.FormValue(formFieldGenTemplate))
+	if err != nil {
+		h.tmpl.ExecuteTemplate(w, templateError, err.Error())
+		return
+	}
 
-	templateName := r.FormValue(formFieldGenTemplate)
-	genTmpl := templates.NewTemplate(templateName)
+	custom, err := getCustomTemplate(r, genTmpl)
+	if err != nil {
+		h.tmpl.ExecuteTemplate(w, templateError, err.Error())
+		return
+	}
+	opts := rejoinder.Options{IDScheme: scheme, Palette: palette, Template: custom}
 
-	out, err := genTmpl.Render(*tableData)
+	// rendering stops if the client disconnects
+	out, err := genTmpl.RenderBytes(r.Context(), doc, opts)
 	if err != nil {
 		h.tmpl.ExecuteTemplate(w, templateError, "Error generating output: "+err.Error())
 		return
 	}
 
-	doc := struct {
-		Content   string
-		Filename  string
-		Extension string
+	result := struct {
+		Content     string
+		DownloadURL template.URL
+		Preview     bool
+		Filename    string
+		Extension   string
+		Warnings    []string
 	}{
-		Content:   out,
+		Preview:   genTmpl.Extension() == previewExtension,
 		Filename:  fileNameWithoutExtension(handler.Filename),
-		Extension: genTmpl.FileExtension(),
+		Extension: genTmpl.Extension(),
+		Warnings:  genTmpl.Warnings(doc, opts),
+	}
+	if genTmpl.Binary() {
+		result.DownloadURL = dataURL(genTmpl.MIMEType(), out)
+	} else {
+		result.Content = string(out)
 	}
 
-	if err := h.tmpl.ExecuteTemplate(w, templateResult, doc); err != nil {
+	if err := h.tmpl.ExecuteTemplate(w, templateResult, result); err != nil {
 		h.tmpl.ExecuteTemplate(w, templateError, "Error rendering results: "+err.Error())
 	}
 }
 
+// readTable reads the uploaded file as document. For workbooks, the given sheet is read
+// and the names of all sheets are returned; for other formats the list of sheets is empty.
+func readTable(file multipart.File, filename, sheet string) (*rejoinder.Document, []string, error) {
+	sheets, err := rejoinder.Sheets(file, filename)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error reading file as table data: %w", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, nil, fmt.Errorf("Error reading file as table data: %w", err)
+	}
+
+	doc, err := rejoinder.Read(file, filename, rejoinder.ReadOptions{Sheet: sheet})
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error reading file as table data: %w", err)
+	}
+	return doc, sheets, nil
+}
+
 func sortHeaders(selectedHeaders []string, originalOrder []string) []string {
 	var ordered []string
 	for _, header := range originalOrder {
@@ -181,6 +287,74 @@ func (h *Handler) getFormFile(r *http.Request) (multipart.File, *multipart.FileH
 	return file, handler, nil
 }
 
+// getCustomTemplate retrieves the optional custom template from the request and validates it
+// as source of the template. Without an uploaded custom template, it returns nil.
+func getCustomTemplate(r *http.Request, tmpl rejoinder.Template) (*rejoinder.CustomTemplate, error) {
+	file, handler, err := r.FormFile(formFieldCustomTmpl)
+	if errors.Is(err, http.ErrMissingFile) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving the custom template: %w", err)
+	}
+	defer file.Close()
+	ctx, cancel := context.WithTimeout(r.Context(), customTemplateTimeout)
+	defer cancel()
+	return readCustomTemplate(ctx, file, handler.Filename, tmpl)
+}
+
+// readCustomTemplate reads the uploaded custom template and validates it as source of the template.
+// Validation stops with the error of the context once the context is done.
+func readCustomTemplate(ctx context.Context, file multipart.File, filename string, tmpl rejoinder.Template) (*rejoinder.CustomTemplate, error) {
+	text, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading the custom template: %w", err)
+	}
+	custom, err := rejoinder.NewCustomTemplate(filename, text)
+	if err != nil {
+		return nil, err
+	}
+	if err := tmpl.ValidateCustom(ctx, custom); err != nil {
+		return nil, fmt.Errorf("invalid custom template: %w", err)
+	}
+	return custom, nil
+}
+
+// Order modes of the form that take an argument.
+const (
+	orderModeColumn    = "column"
+	orderModeReviewers = "reviewers"
+)
+
+// parseOrder returns the order of the responses, see rejoinder.Selection.Order, from the order mode
+// of the form and, depending on the mode, the sort column or the list of reviewers.
+func parseOrder(form url.Values) (string, error) {
+	mode := form.Get(formFieldOrder)
+	switch mode {
+	case orderModeColumn:
+		mode += "=" + form.Get(formFieldOrderColumn)
+	case orderModeReviewers:
+		mode += "=" + form.Get(formFieldOrderList)
+	}
+	if err := (rejoinder.Selection{Order: mode}).Validate(); err != nil {
+		return "", err
+	}
+	return mode, nil
+}
+
+// parseRoles parses the roles of the selected columns from the form.
+func parseRoles(form url.Values, headers []string) (map[string]rejoinder.Role, error) {
+	roles := make(map[string]rejoinder.Role)
+	for _, h := range headers {
+		role, err := rejoinder.ParseRole(form.Get(rolePrefix + h))
+		if err != nil {
+			return nil, fmt.Errorf("column %q: %w", h, err)
+		}
+		roles[h] = role
+	}
+	return roles, nil
+}
+
 // getFormValuesWithPrefix extracts values from a form whose keys have a given prefix.
 func getFormValuesWithPrefix(formValues url.Values, prefix string) []string {
 	var values []string
@@ -199,6 +373,11 @@ func isAllowedContentType(ct string) bool {
 		ct == "application/vnd.ms-excel"
 }
 
+// dataURL encodes binary content as base64 data URL, so it can be downloaded without a second request.
+func dataURL(mimeType string, content []byte) template.URL {
+	return template.URL("data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(content))
+}
+
 func fileNameWithoutExtension(fileName string) string {
 	if pos := strings.LastIndexByte(fileName, '.'); pos != -1 {
 		return fileName[:pos]
@@ -1,8 +1,20 @@
 package server
 
 import (
+	"bytes"
+	"context"
+	"html/template"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
 	"net/url"
+	"strings"
+	"reflect"
 	"testing"
+
+	"github.com/andreas-bauer/rejoinderoo/rejoinder"
+	"github.com/xuri/excelize/v2"
 )
 
 func TestFileNameWithoutExtension(t *testing.T) {
@@ -77,3 +89,170 @@ func TestGetFormValuesWithPrefix(t *testing.T) {
 		t.Errorf("getFormValuesWithPrefix(headers, \"header-\") = %v; want both id and comment", got)
 	}
 }
+
+func TestDataURL(t *testing.T) {
+	got := dataURL("application/zip", []byte("hello"))
+	want := "data:application/zip;base64,aGVsbG8="
+	if string(got) != want {
+		t.Errorf("dataURL() = %q; want %q", got, want)
+	}
+}
+
+// memFile is an in-memory multipart.File.
+type memFile struct {
+	*bytes.Reader
+}
+
+func (memFile) Close() error { return nil }
+
+func TestReadTable(t *testing.T) {
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := f.SetSheetName("Sheet1", "Round 1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.NewSheet("Round 2"); err != nil {
+		t.Fatal(err)
+	}
+	f.SetCellValue("Round 1", "A1", "ID-1")
+	f.SetCellValue("Round 2", "A1", "ID-2")
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc, sheets, err := readTable(memFile{bytes.NewReader(buf.Bytes())}, "reviews.xlsx", "Round 2")
+	if err != nil {
+		t.Fatalf("readTable() error = %v", err)
+	}
+	if !reflect.DeepEqual(sheets, []string{"Round 1", "Round 2"}) {
+		t.Errorf("readTable() sheets = %v", sheets)
+	}
+	if !reflect.DeepEqual(doc.Headers(), []string{"ID-2"}) {
+		t.Errorf("readTable() headers = %v; want headers of second sheet", doc.Headers())
+	}
+
+	doc, sheets, err = readTable(memFile{bytes.NewReader([]byte("ID,Comment\n"))}, "reviews.csv", "ignored")
+	if err != nil {
+		t.Fatalf("readTable() error = %v", err)
+	}
+	if sheets != nil || !reflect.DeepEqual(doc.Headers(), []string{"ID", "Comment"}) {
+		t.Errorf("readTable() for CSV = %v, %v", doc.Headers(), sheets)
+	}
+}
+
+func TestParseRoles(t *testing.T) {
+	form := url.Values{
+		"role-No":     {"id"},
+		"role-Remark": {"Comment"},
+		"role-Status": {"hidden"},
+		"role-Other":  {"id"},
+	}
+	got, err := parseRoles(form, []string{"No", "Remark", "Status", "Answer"})
+	if err != nil {
+		t.Fatalf("parseRoles() error = %v", err)
+	}
+	want := map[string]rejoinder.Role{
+		"No":     rejoinder.IDRole,
+		"Remark": rejoinder.CommentRole,
+		"Status": rejoinder.HiddenRole,
+		"Answer": rejoinder.NoRole,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseRoles() = %v; want %v", got, want)
+	}
+
+	if _, err := parseRoles(url.Values{"role-No": {"boss"}}, []string{"No"}); err == nil {
+		t.Errorf("parseRoles() expected error for unknown role")
+	}
+}
+
+func TestParseOrder(t *testing.T) {
+	tests := []struct {
+		form     url.Values
+		expected string
+		wantErr  bool
+	}{
+		{url.Values{}, "", false},
+		{url.Values{"order": {"id"}, "order-column": {"Status"}}, "id", false},
+		{url.Values{"order": {"column"}, "order-column": {"Status"}}, "column=Status", false},
+		{url.Values{"order": {"reviewers"}, "reviewer-order": {"Editor, R2"}}, "reviewers=Editor, R2", false},
+		{url.Values{"order": {"reviewers"}}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.form.Encode(), func(t *testing.T) {
+			got, err := parseOrder(tt.form)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseOrder() error = %v; wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.expected {
+				t.Errorf("parseOrder() = %q; want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestReadCustomTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		text     string
+		wantErr  bool
+	}{
+		{"Valid", "LaTeX", "{{ .Meta.Title }}", false},
+		{"Parse error", "LaTeX", "{{ range .Rounds }}", true},
+		{"Unknown field", "Markdown", "{{ .Rounds }}", true},
+		{"Not UTF-8", "Typst", "\xff\xfe", true},
+		{"Unsupported template", "DOCX", "{{ .Meta.Title }}", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := rejoinder.LookupTemplate(tt.template)
+			if err != nil {
+				t.Fatalf("LookupTemplate() error = %v", err)
+			}
+			custom, err := readCustomTemplate(context.Background(), memFile{bytes.NewReader([]byte(tt.text))}, "house.tmpl", tmpl)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readCustomTemplate() error = %v; want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (custom.Name != "house.tmpl" || custom.Text != tt.text) {
+				t.Errorf("readCustomTemplate() = %+v", custom)
+			}
+		})
+	}
+}
+
+func TestGenerate_RecursiveCustomTemplate(t *testing.T) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	upload := func(field, filename, contentType, content string) {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", `form-data; name="`+field+`"; filename="`+filename+`"`)
+		header.Set("Content-Type", contentType)
+		part, err := form.CreatePart(header)
+		if err != nil {
+			t.Fatalf("CreatePart() error = %v", err)
+		}
+		part.Write([]byte(content))
+	}
+	upload(formFieldFile, "review.csv", "text/csv", "ID,Comment,Response\nRev1.1,Typo.,Fixed.\n")
+	upload(formFieldCustomTmpl, "house.tmpl", "text/plain", `{{ define "loop" }}x{{ template "loop" }}{{ end }}{{ template "loop" }}`)
+	for _, header := range []string{"ID", "Comment", "Response"} {
+		form.WriteField(headerPrefix+header, header)
+	}
+	form.WriteField(formFieldGenTemplate, "Markdown")
+	form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/generate", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	rec := httptest.NewRecorder()
+	html := template.Must(template.New(templateError).Parse(`error: {{ . }}`))
+	template.Must(html.New(templateResult).Parse(`result: {{ .Content }}`))
+	NewHandler(html).Generate(rec, req)
+
+	if got := rec.Body.String(); !strings.HasPrefix(got, "error: invalid custom template") {
+		t.Errorf("Generate() = %.200q; want error for the recursive custom template", got)
+	}
+}
@@ -0,0 +1,207 @@
+// Package cellmarkup parses the lightweight markup that authors write in spreadsheet cells,
+// a subset of CommonMark, and renders it with the markup of a template.
+//
+// Supported are paragraphs separated by blank lines, bullet lists (-, *, +),
+// ordered lists (1. or 1)), **strong** and *emphasized* text, `code`, and [links](url).
+package cellmarkup
+
+import (
+	"strings"
+)
+
+// BlockKind is the kind of a block.
+type BlockKind int
+
+const (
+	Paragraph BlockKind = iota
+	BulletList
+	OrderedList
+)
+
+// Block is a paragraph or a list.
+// Paragraphs hold their content in Inlines, lists hold one inline sequence per item in Items.
+type Block struct {
+	Kind    BlockKind
+	Inlines []Inline
+	Items   [][]Inline
+}
+
+// InlineKind is the kind of an inline element.
+type InlineKind int
+
+const (
+	Text InlineKind = iota
+	Strong
+	Emph
+	Code
+	Link
+)
+
+// Inline is an element within a paragraph or list item.
+// Text and Code hold their content in Text, all others in Children.
+type Inline struct {
+	Kind     InlineKind
+	Text     string
+	URL      string
+	Children []Inline
+}
+
+// Parse parses the cell text into blocks.
+func Parse(text string) []Block {
+	var blocks []Block
+	var para []string
+
+	flushPara := func() {
+		if len(para) > 0 {
+			blocks = append(blocks, Block{Kind: Paragraph, Inlines: ParseInline(strings.Join(para, "\n"))})
+			para = nil
+		}
+	}
+
+	var list *Block
+	var item []string
+	flushItem := func() {
+		if list != nil && item != nil {
+			list.Items = append(list.Items, ParseInline(strings.Join(item, " ")))
+			item = nil
+		}
+	}
+	flushList := func() {
+		flushItem()
+		if list != nil {
+			blocks = append(blocks, *list)
+			list = nil
+		}
+	}
+
+	for line := range strings.SplitSeq(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			flushPara()
+			flushList()
+			continue
+		}
+
+		if kind, content, ok := listItem(trimmed); ok {
+			flushPara()
+			if list != nil && list.Kind != kind {
+				flushList()
+			}
+			flushItem()
+			if list == nil {
+				list = &Block{Kind: kind}
+			}
+			item = []string{content}
+			continue
+		}
+
+		if list != nil && line != trimmed {
+			// indented continuation of the list item
+			item = append(item, trimmed)
+			continue
+		}
+
+		flushList()
+		para = append(para, trimmed)
+	}
+	flushPara()
+	flushList()
+	return blocks
+}
+
+// listItem reports whether the line starts a list item and returns its kind and content.
+func listItem(line string) (BlockKind, string, bool) {
+	for _, marker := range []string{"- ", "* ", "+ "} {
+		if content, ok := strings.CutPrefix(line, marker); ok {
+			return BulletList, strings.TrimSpace(content), true
+		}
+	}
+
+	digits := len(line) - len(strings.TrimLeft(line, "0123456789"))
+	if digits == 0 || digits > 9 || len(line) < digits+2 {
+		return 0, "", false
+	}
+	if (line[digits] == '.' || line[digits] == ')') && line[digits+1] == ' ' {
+		return OrderedList, strings.TrimSpace(line[digits+2:]), true
+	}
+	return 0, "", false
+}
+
+// ParseInline parses the inline elements of the text.
+// Unmatched delimiters are kept as text; a backslash escapes the following punctuation.
+func ParseInline(text string) []Inline {
+	var res []Inline
+	var sb strings.Builder
+
+	flush := func() {
+		if sb.Len() > 0 {
+			res = append(res, Inline{Kind: Text, Text: sb.String()})
+			sb.Reset()
+		}
+	}
+
+	for i := 0; i < len(text); {
+		rest := text[i:]
+		switch {
+		case rest[0] == '\\' && len(rest) > 1 && strings.ContainsRune("\\`*_[]()#+-.!", rune(rest[1])):
+			sb.WriteByte(rest[1])
+			i += 2
+			continue
+
+		case strings.HasPrefix(rest, "**"):
+			if end := strings.Index(rest[2:], "**"); end > 0 {
+				flush()
+				res = append(res, Inline{Kind: Strong, Children: ParseInline(rest[2 : 2+end])})
+				i += end + 4
+				continue
+			}
+
+		case rest[0] == '*' && len(rest) > 1 && rest[1] != ' ':
+			if end := strings.IndexByte(rest[1:], '*'); end > 0 && rest[end] != ' ' {
+				flush()
+				res = append(res, Inline{Kind: Emph, Children: ParseInline(rest[1 : 1+end])})
+				i += end + 2
+				continue
+			}
+
+		case rest[0] == '`':
+			if end := strings.IndexByte(rest[1:], '`'); end > 0 {
+				flush()
+				res = append(res, Inline{Kind: Code, Text: rest[1 : 1+end]})
+				i += end + 2
+				continue
+			}
+
+		case rest[0] == '[':
+			if label, url, n, ok := link(rest); ok {
+				flush()
+				res = append(res, Inline{Kind: Link, URL: url, Children: ParseInline(label)})
+				i += n
+				continue
+			}
+		}
+
+		sb.WriteByte(rest[0])
+		i++
+	}
+	flush()
+	return res
+}
+
+// link parses a link of the form [label](url) at the start of the text
+// and returns its label, URL, and length.
+func link(text string) (string, string, int, bool) {
+	closing := strings.Index(text, "](")
+	if closing < 1 {
+		return "", "", 0, false
+	}
+	end := strings.IndexByte(text[closing+2:], ')')
+	if end < 1 {
+		return "", "", 0, false
+	}
+	url := text[closing+2 : closing+2+end]
+	if strings.ContainsAny(url, " \n") {
+		return "", "", 0, false
+	}
+	return text[1:closing], url, closing + end + 3, true
+}
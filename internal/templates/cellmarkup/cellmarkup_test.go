@@ -0,0 +1,152 @@
+package cellmarkup
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	text := func(s string) []Inline { return []Inline{{Kind: Text, Text: s}} }
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []Block
+	}{
+		{
+			name:     "single paragraph",
+			input:    "Just text.",
+			expected: []Block{{Kind: Paragraph, Inlines: text("Just text.")}},
+		},
+		{
+			name:  "paragraphs separated by blank lines",
+			input: "First line\nsecond line\n \nNext paragraph",
+			expected: []Block{
+				{Kind: Paragraph, Inlines: text("First line\nsecond line")},
+				{Kind: Paragraph, Inlines: text("Next paragraph")},
+			},
+		},
+		{
+			name:  "paragraph followed by bullet list",
+			input: "The figures are mentioned:\n- Figure 1\n- Figure 2\n  on page 13",
+			expected: []Block{
+				{Kind: Paragraph, Inlines: text("The figures are mentioned:")},
+				{Kind: BulletList, Items: [][]Inline{text("Figure 1"), text("Figure 2 on page 13")}},
+			},
+		},
+		{
+			name:  "ordered list after bullet list",
+			input: "* a\n+ b\n1. first\n2) second",
+			expected: []Block{
+				{Kind: BulletList, Items: [][]Inline{text("a"), text("b")}},
+				{Kind: OrderedList, Items: [][]Inline{text("first"), text("second")}},
+			},
+		},
+		{
+			name:  "paragraph after list",
+			input: "- item\nNot indented",
+			expected: []Block{
+				{Kind: BulletList, Items: [][]Inline{text("item")}},
+				{Kind: Paragraph, Inlines: text("Not indented")},
+			},
+		},
+		{
+			name:     "numbers without list marker",
+			input:    "2024 was a year.\n3.5 is a number",
+			expected: []Block{{Kind: Paragraph, Inlines: text("2024 was a year.\n3.5 is a number")}},
+		},
+		{
+			name:     "empty text",
+			input:    "",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Parse(tt.input)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Parse(%q) = %+v; want %+v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseInline(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []Inline
+	}{
+		{
+			name:  "strong and emphasized",
+			input: "a **bold** and *emph* word",
+			expected: []Inline{
+				{Kind: Text, Text: "a "},
+				{Kind: Strong, Children: []Inline{{Kind: Text, Text: "bold"}}},
+				{Kind: Text, Text: " and "},
+				{Kind: Emph, Children: []Inline{{Kind: Text, Text: "emph"}}},
+				{Kind: Text, Text: " word"},
+			},
+		},
+		{
+			name:  "code is literal",
+			input: "run `go test **` now",
+			expected: []Inline{
+				{Kind: Text, Text: "run "},
+				{Kind: Code, Text: "go test **"},
+				{Kind: Text, Text: " now"},
+			},
+		},
+		{
+			name:  "link with strong label",
+			input: "[**docs**](https://example.com)",
+			expected: []Inline{
+				{Kind: Link, URL: "https://example.com", Children: []Inline{
+					{Kind: Strong, Children: []Inline{{Kind: Text, Text: "docs"}}},
+				}},
+			},
+		},
+		{
+			name:     "unmatched delimiters",
+			input:    "2 * 3 = 6, [see](no url, **open",
+			expected: []Inline{{Kind: Text, Text: "2 * 3 = 6, [see](no url, **open"}},
+		},
+		{
+			name:     "escaped delimiters",
+			input:    `\*not emph\*`,
+			expected: []Inline{{Kind: Text, Text: "*not emph*"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseInline(tt.input)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ParseInline(%q) = %+v; want %+v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	f := Format{
+		Escape: strings.ToUpper,
+		Strong: func(s string) string { return "<b>" + s + "</b>" },
+		Emph:   func(s string) string { return "<i>" + s + "</i>" },
+		Code:   func(s string) string { return "<code>" + s + "</code>" },
+		Link:   func(url, s string) string { return fmt.Sprintf("<a %s>%s</a>", url, s) },
+		List: func(ordered bool, items []string) string {
+			return fmt.Sprintf("list(%v)%q", ordered, items)
+		},
+		CallEnd: ";",
+	}
+
+	input := "See **this** and `code`:\n1. [docs](u).\n2. *two*\n\nEnd"
+	expected := "SEE <b>THIS</b> AND <code>code</code>:\n\nlist(true)[\"<a u>DOCS</a>;.\" \"<i>TWO</i>\"]\n\nEND"
+	if got := Render(input, f); got != expected {
+		t.Errorf("Render() = %q; want %q", got, expected)
+	}
+}
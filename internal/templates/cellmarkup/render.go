@@ -0,0 +1,60 @@
+package cellmarkup
+
+import "strings"
+
+// Format describes how a template writes the markup elements.
+// Escape is applied to all text, except code, which Code escapes itself.
+type Format struct {
+	Escape func(text string) string
+	Strong func(content string) string
+	Emph   func(content string) string
+	Code   func(code string) string
+	Link   func(url, content string) string
+	// List writes a list with the given, already rendered, items.
+	List func(ordered bool, items []string) string
+	// CallEnd is appended after a link if the following text starts with '.', '(' or '[',
+	// e.g., Typst needs ';' to end the function call.
+	CallEnd string
+}
+
+// Render parses the cell text and renders it in the given format.
+// Blocks are separated by a blank line.
+func Render(text string, f Format) string {
+	blocks := Parse(text)
+	res := make([]string, len(blocks))
+	for i, b := range blocks {
+		switch b.Kind {
+		case Paragraph:
+			res[i] = renderInlines(b.Inlines, f)
+		default:
+			items := make([]string, len(b.Items))
+			for j, item := range b.Items {
+				items[j] = renderInlines(item, f)
+			}
+			res[i] = f.List(b.Kind == OrderedList, items)
+		}
+	}
+	return strings.Join(res, "\n\n")
+}
+
+func renderInlines(inlines []Inline, f Format) string {
+	var sb strings.Builder
+	for i, in := range inlines {
+		switch in.Kind {
+		case Text:
+			sb.WriteString(f.Escape(in.Text))
+		case Strong:
+			sb.WriteString(f.Strong(renderInlines(in.Children, f)))
+		case Emph:
+			sb.WriteString(f.Emph(renderInlines(in.Children, f)))
+		case Code:
+			sb.WriteString(f.Code(in.Text))
+		case Link:
+			sb.WriteString(f.Link(in.URL, renderInlines(in.Children, f)))
+			if i+1 < len(inlines) && inlines[i+1].Kind == Text && strings.IndexAny(inlines[i+1].Text, ".([") == 0 {
+				sb.WriteString(f.CallEnd)
+			}
+		}
+	}
+	return sb.String()
+}
@@ -1,24 +1,13 @@
 package common
 
-import "strings"
-
-
+// ExtractReviewers returns the reviewers of the records according to the default ID scheme.
 func ExtractReviewers(records [][]string) []string {
-	var allRevIDs []string
-	for _, rec := range records {
-		if len(rec) < 1 {
-			continue
-		}
-		refID := ExtractReviewerID(rec[0])
-		if SearchSlice(allRevIDs, refID) == -1 {
-			allRevIDs = append(allRevIDs, refID)
-		}
-	}
-	return allRevIDs
+	return DefaultIDScheme.Reviewers(records)
 }
 
+// ExtractReviewerID returns the reviewer of the ID according to the default ID scheme.
 func ExtractReviewerID(fullID string) string {
-	return strings.Split(strings.Split(strings.Split(strings.Split(fullID, ".")[0], "-")[0], ":")[0], " ")[0]
+	return DefaultIDScheme.ReviewerID(fullID)
 }
 
 // SearchSlice is a generic linear search function that works for any slice type
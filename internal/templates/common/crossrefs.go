@@ -0,0 +1,187 @@
+package common
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CrossRefs finds the IDs of the first column in the text of other cells,
+// e.g., "see our answer to Rev1.3", to link them to the corresponding response.
+// Only IDs that the ID scheme parses into a reviewer and a comment are linked, so that
+// numeric IDs, e.g., with a reviewer column, do not turn "Section 2" into a link.
+// A nil *CrossRefs finds no references.
+type CrossRefs struct {
+	ids       []string // longest first, so that Rev1.10 is preferred over Rev1.1
+	labels    map[refKey]string
+	first     map[string]string // label of the first record with the ID
+	reviewers map[string]bool
+	scheme    *IDScheme
+	reviewer  string // whose IDs are preferred, see Of
+}
+
+// refKey identifies a record by its reviewer and ID, since reviewers may use the same IDs.
+type refKey struct {
+	reviewer, id string
+}
+
+// RefFormat converts cross-references to the markup of a template.
+type RefFormat struct {
+	// Escape escapes the ID, which is the text of the link.
+	Escape func(text string) string
+	// Link links the escaped text to the response with the given label.
+	Link func(text, label string) string
+	// CallEnd is appended to a link that the following text could continue, see cellmarkup.Format.
+	CallEnd string
+}
+
+// refCandidate matches text that looks like an ID, e.g., Rev1.3, R1:3, or AE-R2-C14.
+var refCandidate = regexp.MustCompile(`[A-Za-z][A-Za-z0-9]*(?:[.\-:][A-Za-z0-9]+)+`)
+
+// NewCrossRefs collects the IDs of the records and their labels, see Labels.
+// The ID scheme tells references to unknown IDs apart from other text.
+func NewCrossRefs(records [][]string, labels []string, scheme *IDScheme) *CrossRefs {
+	c := &CrossRefs{
+		labels:    make(map[refKey]string),
+		first:     make(map[string]string),
+		reviewers: make(map[string]bool),
+		scheme:    scheme,
+	}
+	for i, rec := range records {
+		if len(rec) < 1 {
+			continue
+		}
+		id := strings.TrimSpace(rec[0])
+		if id == "" {
+			continue
+		}
+		var label string
+		if i < len(labels) {
+			label = labels[i]
+		}
+		if key := (refKey{scheme.RecordReviewer(rec), id}); !hasKey(c.labels, key) {
+			c.labels[key] = label
+		}
+		if _, ok := c.first[id]; ok {
+			continue
+		}
+		c.first[id] = label
+		if r := scheme.ReviewerID(id); r != "" {
+			c.reviewers[r] = true
+		}
+	}
+	for id := range c.first {
+		if c.linkable(id) {
+			c.ids = append(c.ids, id)
+		}
+	}
+	slices.SortFunc(c.ids, func(a, b string) int {
+		return cmp.Or(cmp.Compare(len(b), len(a)), strings.Compare(a, b))
+	})
+	return c
+}
+
+func hasKey(m map[refKey]string, key refKey) bool {
+	_, ok := m[key]
+	return ok
+}
+
+// linkable reports whether the ID scheme parses the text into a known reviewer and a comment.
+func (c *CrossRefs) linkable(text string) bool {
+	reviewer, comment, ok := c.scheme.Parse(text)
+	return ok && comment != "" && c.reviewers[reviewer]
+}
+
+// Of returns the cross-references in the cells of a record of the given reviewer:
+// IDs that several reviewers use link to the response of that reviewer.
+func (c *CrossRefs) Of(reviewer string) *CrossRefs {
+	if c == nil {
+		return nil
+	}
+	res := *c
+	res.reviewer = reviewer
+	return &res
+}
+
+// label returns the label of the response with the ID, preferring the reviewer of Of.
+func (c *CrossRefs) label(id string) string {
+	if label, ok := c.labels[refKey{c.reviewer, id}]; ok {
+		return label
+	}
+	return c.first[id]
+}
+
+// Protect replaces the references in the text with placeholders, see Escaping.Protect.
+// The returned function puts the links into the converted text.
+func (c *CrossRefs) Protect(text string, f RefFormat) (string, func(string) string) {
+	if c == nil || len(c.ids) == 0 {
+		return text, func(s string) string { return s }
+	}
+
+	var sb strings.Builder
+	var pairs []string
+	for i := 0; i < len(text); {
+		id := c.refAt(text, i)
+		if id == "" {
+			sb.WriteByte(text[i])
+			i++
+			continue
+		}
+		i += len(id)
+		link := f.Link(f.Escape(id), c.label(id))
+		if i < len(text) && strings.IndexByte(".([", text[i]) >= 0 {
+			link += f.CallEnd
+		}
+		placeholder := fmt.Sprintf("%cref%d%c", PlaceholderOpen, len(pairs)/2, PlaceholderClose)
+		pairs = append(pairs, placeholder, link)
+		sb.WriteString(placeholder)
+	}
+	if len(pairs) == 0 {
+		return text, func(s string) string { return s }
+	}
+	return sb.String(), strings.NewReplacer(pairs...).Replace
+}
+
+// refAt returns the ID that starts at position i of the text as a whole word, or an empty string.
+func (c *CrossRefs) refAt(text string, i int) string {
+	if prev, _ := utf8.DecodeLastRuneInString(text[:i]); i > 0 && isWordRune(prev) {
+		return ""
+	}
+	for _, id := range c.ids {
+		if !strings.HasPrefix(text[i:], id) {
+			continue
+		}
+		if next, _ := utf8.DecodeRuneInString(text[i+len(id):]); i+len(id) < len(text) && isWordRune(next) {
+			continue
+		}
+		return id
+	}
+	return ""
+}
+
+// Unknown returns the references in the text to IDs that do not exist.
+// Only text that the ID scheme parses into a known reviewer and a comment counts as reference,
+// e.g., Rev1.9 if there is a Rev1.1 but no Rev1.9.
+func (c *CrossRefs) Unknown(text string) []string {
+	if c == nil {
+		return nil
+	}
+	var res []string
+	for _, candidate := range refCandidate.FindAllString(text, -1) {
+		if _, ok := c.first[candidate]; ok || slices.Contains(res, candidate) {
+			continue
+		}
+		if c.linkable(candidate) {
+			res = append(res, candidate)
+		}
+	}
+	return res
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,98 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCrossRefs_Protect(t *testing.T) {
+	records := [][]string{
+		{"Rev1.1"},
+		{"Rev1.10"},
+		{"Reviewer 2: 1"},
+		{},
+	}
+	refs := NewCrossRefs(records, Labels("", records), nil)
+	format := RefFormat{
+		Escape:  func(text string) string { return "<" + text + ">" },
+		Link:    func(text, label string) string { return "link(" + label + ")[" + text + "]" },
+		CallEnd: ";",
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"single reference", "see Rev1.1", "see link(Rev1.1)[<Rev1.1>]"},
+		{"longest ID", "see Rev1.10 and Rev1.1", "see link(Rev1.10)[<Rev1.10>] and link(Rev1.1)[<Rev1.1>]"},
+		{"ID with spaces", "as for Reviewer 2: 1", "as for link(Reviewer2:1)[<Reviewer 2: 1>]"},
+		{"call end", "see Rev1.1.", "see link(Rev1.1)[<Rev1.1>];."},
+		{"within word", "xRev1.1 and Rev1.1x", "xRev1.1 and Rev1.1x"},
+		{"followed by digit", "Rev1.100", "Rev1.100"},
+		{"no reference", "nothing to see", "nothing to see"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			protected, restore := refs.Protect(tt.input, format)
+			if got := restore(protected); got != tt.expected {
+				t.Errorf("Protect(%q) = %q; want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCrossRefs_ProtectReviewerColumn(t *testing.T) {
+	records := [][]string{
+		{"1", "R1"},
+		{"2", "R1"},
+		{"1", "R2"},
+		{"Q.1", "R1"},
+		{"Q.1", "R2"},
+	}
+	refs := NewCrossRefs(records, Labels("", records), DefaultIDScheme.WithReviewerColumn(1))
+	format := RefFormat{
+		Escape: func(text string) string { return text },
+		Link:   func(text, label string) string { return "link(" + label + ")[" + text + "]" },
+	}
+
+	tests := []struct {
+		name     string
+		reviewer string
+		input    string
+		expected string
+	}{
+		{"numeric IDs in prose", "R1", "Fix Section 2 and Table 1.", "Fix Section 2 and Table 1."},
+		{"ID of the same reviewer", "R1", "see Q.1", "see link(Q.1)[Q.1]"},
+		{"duplicate ID of another reviewer", "R2", "see Q.1", "see link(Q.1-2)[Q.1]"},
+		{"unknown reviewer", "", "see Q.1", "see link(Q.1)[Q.1]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			protected, restore := refs.Of(tt.reviewer).Protect(tt.input, format)
+			if got := restore(protected); got != tt.expected {
+				t.Errorf("Protect(%q) = %q; want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCrossRefs_ProtectNil(t *testing.T) {
+	var refs *CrossRefs
+	protected, restore := refs.Protect("see Rev1.1", RefFormat{})
+	if got := restore(protected); got != "see Rev1.1" {
+		t.Errorf("Protect() = %q; want the text unchanged", got)
+	}
+}
+
+func TestCrossRefs_Unknown(t *testing.T) {
+	records := [][]string{{"Rev1.1"}, {"Rev1.2"}, {"Rev2.1"}}
+	refs := NewCrossRefs(records, nil, nil)
+
+	got := refs.Unknown("See Rev1.2, Rev1.9, Rev3.1, e.g., Rev2.5 and Rev1.9 again.")
+	want := []string{"Rev1.9", "Rev2.5"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unknown() = %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,43 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+)
+
+// CustomTemplate is a user-supplied template, e.g., in the house style of an institute,
+// that replaces the built-in template of a format. It is written in the syntax of
+// text/template and receives the same data as the built-in template.
+type CustomTemplate struct {
+	// Name identifies the template in error messages, e.g., the file name.
+	Name string
+	// Text is the source of the template.
+	Text string
+}
+
+// NewCustomTemplate returns a custom template with the given name and source.
+func NewCustomTemplate(name string, text []byte) (*CustomTemplate, error) {
+	if !utf8.Valid(text) {
+		return nil, fmt.Errorf("template %s is not a UTF-8 text file", name)
+	}
+	return &CustomTemplate{Name: name, Text: string(text)}, nil
+}
+
+// LoadCustomTemplate reads a custom template from the file at the given path.
+func LoadCustomTemplate(path string) (*CustomTemplate, error) {
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewCustomTemplate(filepath.Base(path), text)
+}
+
+// Source returns the name and text of the custom template, or the given built-in ones if c is nil.
+func (c *CustomTemplate) Source(name, builtin string) (string, string) {
+	if c == nil {
+		return name, builtin
+	}
+	return c.Name, c.Text
+}
@@ -0,0 +1,36 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadCustomTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "house.tmpl")
+	if err := os.WriteFile(path, []byte("{{ .Meta.Title }}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	custom, err := LoadCustomTemplate(path)
+	if err != nil {
+		t.Fatalf("LoadCustomTemplate() error = %v", err)
+	}
+	if name, text := custom.Source("latex", "builtin"); name != "house.tmpl" || text != "{{ .Meta.Title }}" {
+		t.Errorf("Source() = %q, %q; want the custom template", name, text)
+	}
+
+	if _, err := LoadCustomTemplate(filepath.Join(t.TempDir(), "missing.tmpl")); err == nil {
+		t.Error("LoadCustomTemplate() expected error for missing file")
+	}
+	if _, err := NewCustomTemplate("binary.tmpl", []byte{0xff, 0xfe}); err == nil {
+		t.Error("NewCustomTemplate() expected error for non-UTF-8 text")
+	}
+}
+
+func TestCustomTemplate_SourceNil(t *testing.T) {
+	var custom *CustomTemplate
+	if name, text := custom.Source("latex", "builtin"); name != "latex" || text != "builtin" {
+		t.Errorf("Source() = %q, %q; want the built-in template", name, text)
+	}
+}
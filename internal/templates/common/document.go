@@ -0,0 +1,60 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/andreas-bauer/rejoinderoo/internal/reader"
+)
+
+// Document holds the responses that a template renders: either the records of a single sheet
+// or several review rounds. Exactly one of Data and Rounds is set.
+type Document struct {
+	// Data holds the responses of a single sheet.
+	Data *reader.TabularData
+	// Rounds holds the review rounds in chronological order, e.g., the sheets of a workbook.
+	// Templates that support review rounds render a section per round.
+	Rounds *reader.Workbook
+}
+
+// NewDocument returns a document with the responses of a single sheet.
+func NewDocument(td *reader.TabularData) Document {
+	return Document{Data: td}
+}
+
+// NewRoundsDocument returns a document with a section per review round.
+func NewRoundsDocument(wb *reader.Workbook) Document {
+	return Document{Rounds: wb}
+}
+
+// Single returns the responses of a document without review rounds. The format names
+// the template in the error for documents with several review rounds, which it cannot combine.
+func (d Document) Single(format string) (*reader.TabularData, error) {
+	if d.Rounds != nil {
+		return nil, fmt.Errorf("template %s cannot combine several review rounds", format)
+	}
+	if d.Data == nil {
+		return nil, fmt.Errorf("document does not contain any responses")
+	}
+	return d.Data, nil
+}
+
+// contextWriter fails with the error of the context once the context is done.
+type contextWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+// NewContextWriter returns a writer that fails with the error of the context once the context is done,
+// so that templates stop rendering, e.g., if the client of the web server disconnects.
+func NewContextWriter(ctx context.Context, w io.Writer) io.Writer {
+	return &contextWriter{ctx: ctx, w: w}
+}
+
+func (cw *contextWriter) Write(p []byte) (int, error) {
+	if err := cw.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cw.w.Write(p)
+}
@@ -0,0 +1,45 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/andreas-bauer/rejoinderoo/internal/reader"
+)
+
+func TestDocument_Single(t *testing.T) {
+	td := &reader.TabularData{Headers: []string{"ID"}}
+
+	got, err := NewDocument(td).Single("Markdown")
+	if err != nil || got != td {
+		t.Errorf("Single() = %v, %v; want the tabular data", got, err)
+	}
+
+	_, err = NewRoundsDocument(&reader.Workbook{}).Single("Markdown")
+	if want := "template Markdown cannot combine several review rounds"; err == nil || err.Error() != want {
+		t.Errorf("Single() of review rounds error = %v; want %q", err, want)
+	}
+
+	if _, err := (Document{}).Single("Markdown"); err == nil {
+		t.Error("Single() of an empty document expected error")
+	}
+}
+
+func TestContextWriter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var b strings.Builder
+	w := NewContextWriter(ctx, &b)
+
+	if _, err := w.Write([]byte("before")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	cancel()
+	if _, err := w.Write([]byte("after")); !errors.Is(err, context.Canceled) {
+		t.Errorf("Write() after cancel error = %v; want %v", err, context.Canceled)
+	}
+	if b.String() != "before" {
+		t.Errorf("written = %q; want only the text before cancel", b.String())
+	}
+}
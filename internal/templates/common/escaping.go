@@ -0,0 +1,163 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Escaping selects how templates escape the text of cells.
+type Escaping int
+
+const (
+	// StrictEscaping escapes all special characters of the template's markup.
+	StrictEscaping Escaping = iota
+	// MathEscaping passes inline math ($...$) and raw blocks ({{raw}}...{{/raw}}) through as is
+	// and escapes everything else. A dollar sign that does not start math is escaped with \$.
+	MathEscaping
+)
+
+// Delimiters of the placeholders that Protect inserts. Escape functions must keep them as is.
+const (
+	PlaceholderOpen  = '\uE000'
+	PlaceholderClose = '\uE001'
+)
+
+// Markers of a raw block, whose content is passed through as is in math-aware escaping.
+const (
+	RawOpen  = "{{raw}}"
+	RawClose = "{{/raw}}"
+)
+
+var escapingNames = map[Escaping]string{
+	StrictEscaping: "strict",
+	MathEscaping:   "math",
+}
+
+// EscapingNames returns the names of all escaping modes.
+func EscapingNames() []string {
+	return []string{escapingNames[StrictEscaping], escapingNames[MathEscaping]}
+}
+
+// ParseEscaping returns the escaping mode with the given name. An empty name selects strict escaping.
+func ParseEscaping(name string) (Escaping, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return StrictEscaping, nil
+	}
+	for e, n := range escapingNames {
+		if n == name {
+			return e, nil
+		}
+	}
+	return StrictEscaping, fmt.Errorf("escaping %q is not supported, choose one of %v", name, EscapingNames())
+}
+
+func (e Escaping) String() string {
+	return escapingNames[e]
+}
+
+// Verbatim returns the length of a template-specific block at the start of the text
+// that math-aware escaping passes through as is, or 0 if there is none.
+type Verbatim func(text string) int
+
+// Escape escapes the text with the given function, except for the math and raw blocks
+// that the escaping mode passes through.
+func (e Escaping) Escape(text string, escape func(string) string, verbatim ...Verbatim) string {
+	protected, restore := e.Protect(text, escape, verbatim...)
+	return restore(escape(protected))
+}
+
+// Protect replaces the math and raw blocks of the text, as well as any template-specific
+// verbatim blocks, with placeholders that escaping and markup conversion leave alone.
+// The returned function puts the blocks back into the converted text.
+// The escape function is used for escaped dollar signs.
+// Placeholder delimiters that are already part of the text are removed.
+func (e Escaping) Protect(text string, escape func(string) string, verbatim ...Verbatim) (string, func(string) string) {
+	if strings.ContainsAny(text, string([]rune{PlaceholderOpen, PlaceholderClose})) {
+		// placeholders in the input would be mistaken for protected blocks
+		text = strings.Map(func(r rune) rune {
+			if r == PlaceholderOpen || r == PlaceholderClose {
+				return -1
+			}
+			return r
+		}, text)
+	}
+	if e != MathEscaping || (len(verbatim) == 0 && !strings.ContainsAny(text, "${")) {
+		return text, func(s string) string { return s }
+	}
+
+	var sb strings.Builder
+	var pairs []string
+	protect := func(block string) {
+		placeholder := fmt.Sprintf("%c%d%c", PlaceholderOpen, len(pairs)/2, PlaceholderClose)
+		pairs = append(pairs, placeholder, block)
+		sb.WriteString(placeholder)
+	}
+
+	for i := 0; i < len(text); {
+		rest := text[i:]
+		if strings.HasPrefix(rest, RawOpen) {
+			if end := strings.Index(rest[len(RawOpen):], RawClose); end >= 0 {
+				protect(rest[len(RawOpen) : len(RawOpen)+end])
+				i += len(RawOpen) + end + len(RawClose)
+				continue
+			}
+		}
+		if strings.HasPrefix(rest, `\$`) {
+			protect(escape("$"))
+			i += 2
+			continue
+		}
+		if n := mathLen(rest); n > 0 {
+			protect(rest[:n])
+			i += n
+			continue
+		}
+		if n := verbatimLen(rest, verbatim); n > 0 {
+			protect(rest[:n])
+			i += n
+			continue
+		}
+		sb.WriteByte(text[i])
+		i++
+	}
+
+	restore := strings.NewReplacer(pairs...)
+	return sb.String(), restore.Replace
+}
+
+func verbatimLen(text string, verbatim []Verbatim) int {
+	for _, v := range verbatim {
+		if n := v(text); n > 0 {
+			return n
+		}
+	}
+	return 0
+}
+
+// mathLen returns the length of the inline math at the start of the text, or 0 if there is none.
+// Like in Pandoc, the opening $ must be followed and the closing $ preceded by a non-space character,
+// and the closing $ must not be followed by a digit, so that "$5 and $10" is not math.
+func mathLen(text string) int {
+	if len(text) < 3 || text[0] != '$' || isSpace(text[1]) || text[1] == '$' {
+		return 0
+	}
+	for i := 2; i < len(text); i++ {
+		switch text[i] {
+		case '\n':
+			return 0
+		case '\\':
+			i++ // skip escaped character
+		case '$':
+			if isSpace(text[i-1]) || (i+1 < len(text) && text[i+1] >= '0' && text[i+1] <= '9') {
+				continue
+			}
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
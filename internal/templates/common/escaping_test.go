@@ -0,0 +1,89 @@
+package common
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseEscaping(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Escaping
+		wantErr  bool
+	}{
+		{"", StrictEscaping, false},
+		{"strict", StrictEscaping, false},
+		{" Math ", MathEscaping, false},
+		{"latex", StrictEscaping, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseEscaping(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseEscaping(%q) error = %v; wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseEscaping(%q) = %v; want %v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEscaping_Escape(t *testing.T) {
+	// marks escaped text, so that passed through text is easy to spot
+	escape := func(s string) string {
+		return strings.NewReplacer("$", "\\$", "%", "\\%", "\\", "\\textbackslash{}").Replace(s)
+	}
+
+	tests := []struct {
+		name     string
+		escaping Escaping
+		input    string
+		expected string
+	}{
+		{"strict escapes math", StrictEscaping, "$p < 0.05$ at 5%", "\\$p < 0.05\\$ at 5\\%"},
+		{"strict escapes raw", StrictEscaping, "{{raw}}\\cite{a}{{/raw}}", "{{raw}}\\textbackslash{}cite{a}{{/raw}}"},
+		{"inline math", MathEscaping, "significant ($p < 0.05$) at 5%", "significant ($p < 0.05$) at 5\\%"},
+		{"math with escaped dollar", MathEscaping, "$a \\$ b$", "$a \\$ b$"},
+		{"currency is not math", MathEscaping, "costs $5 and $10", "costs \\$5 and \\$10"},
+		{"space after opening dollar", MathEscaping, "$ x$", "\\$ x\\$"},
+		{"escaped dollar", MathEscaping, "\\$x$", "\\$x\\$"},
+		{"unclosed math", MathEscaping, "$x", "\\$x"},
+		{"math does not span lines", MathEscaping, "$x\ny$", "\\$x\ny\\$"},
+		{"raw block", MathEscaping, "see {{raw}}\\cite{a}{{/raw}} 100%", "see \\cite{a} 100\\%"},
+		{"raw block spans lines", MathEscaping, "{{raw}}\\begin{x}\n50%\n\\end{x}{{/raw}}", "\\begin{x}\n50%\n\\end{x}"},
+		{"unclosed raw block", MathEscaping, "{{raw}}\\cite", "{{raw}}\\textbackslash{}cite"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.escaping.Escape(tt.input, escape)
+			if got != tt.expected {
+				t.Errorf("Escape(%q) = %q; want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEscaping_EscapeVerbatim(t *testing.T) {
+	escape := func(s string) string { return strings.ReplaceAll(s, "@", "\\@") }
+	// passes through words starting with an exclamation mark
+	word := func(text string) int {
+		if !strings.HasPrefix(text, "!") {
+			return 0
+		}
+		if end := strings.IndexByte(text, ' '); end >= 0 {
+			return end
+		}
+		return len(text)
+	}
+
+	input := "@user !keep@as-is @end"
+	if got := MathEscaping.Escape(input, escape, word); got != "\\@user !keep@as-is \\@end" {
+		t.Errorf("MathEscaping.Escape(%q) = %q", input, got)
+	}
+	if got := StrictEscaping.Escape(input, escape, word); got != "\\@user !keep\\@as-is \\@end" {
+		t.Errorf("StrictEscaping.Escape(%q) = %q", input, got)
+	}
+}
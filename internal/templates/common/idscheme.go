@@ -0,0 +1,201 @@
+package common
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// IDScheme parses comment IDs with a regular expression into the reviewer and the comment number.
+// The expression must contain the named group "reviewer" and may contain the named group "comment".
+// A nil *IDScheme is the default scheme.
+type IDScheme struct {
+	// Name is the name of the preset, or empty for custom schemes.
+	Name    string
+	Pattern string
+	re      *regexp.Regexp
+	// reviewerColumn is the index plus one of the column with the reviewer of each record, or 0 if there is none.
+	reviewerColumn int
+}
+
+// idSchemePresets are the predefined ID schemes, selectable by name.
+var idSchemePresets = []struct {
+	name, pattern, example string
+}{
+	// reviewer is the text before the first '.', '-', ':', or space, matching every ID
+	{"default", `(?s)^(?P<reviewer>[^.\-: ]*)(?:[.\-: ](?P<comment>.*))?$`, "Rev1.2"},
+	{"rev-dot", `^(?P<reviewer>[A-Za-z]+\d+)\.(?P<comment>\d+)$`, "Rev1.2"},
+	// the reviewer keeps the prefix, so that AE-R1 and R1 are different reviewers
+	{"reviewer-comment", `^(?P<reviewer>(?:[A-Za-z]+-)?R\d+)-C(?P<comment>\d+)$`, "AE-R2-C14"},
+	{"verbose", `(?i)^(?P<reviewer>Reviewer\s*#?\s*\d+)\s*/\s*Comment\s*#?\s*(?P<comment>\d+)$`, "Reviewer #2 / Comment 3"},
+}
+
+// DefaultIDScheme splits IDs at the first '.', '-', ':', or space, e.g., Rev1.2 into Rev1 and 2.
+var DefaultIDScheme = mustIDScheme(idSchemePresets[0].name, idSchemePresets[0].pattern)
+
+// IDSchemePresets returns the names of the predefined ID schemes.
+func IDSchemePresets() []string {
+	res := make([]string, len(idSchemePresets))
+	for i, p := range idSchemePresets {
+		res[i] = p.name
+	}
+	return res
+}
+
+// IDSchemeExample returns an example ID of the predefined ID scheme, or an empty string.
+func IDSchemeExample(name string) string {
+	for _, p := range idSchemePresets {
+		if p.name == name {
+			return p.example
+		}
+	}
+	return ""
+}
+
+// ParseIDScheme returns the predefined ID scheme with the given name or,
+// if there is none, compiles the given regular expression. An empty string selects the default scheme.
+func ParseIDScheme(scheme string) (*IDScheme, error) {
+	scheme = strings.TrimSpace(scheme)
+	if scheme == "" {
+		return DefaultIDScheme, nil
+	}
+	for _, p := range idSchemePresets {
+		if strings.EqualFold(p.name, scheme) {
+			return NewIDScheme(p.name, p.pattern)
+		}
+	}
+	return NewIDScheme("", scheme)
+}
+
+// NewIDScheme compiles the regular expression of an ID scheme.
+func NewIDScheme(name, pattern string) (*IDScheme, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("ID scheme %q: %w", pattern, err)
+	}
+	if !slices.Contains(re.SubexpNames(), "reviewer") {
+		return nil, fmt.Errorf("ID scheme %q: missing named group (?P<reviewer>...), presets are %v", pattern, IDSchemePresets())
+	}
+	return &IDScheme{Name: name, Pattern: pattern, re: re}, nil
+}
+
+func mustIDScheme(name, pattern string) *IDScheme {
+	s, err := NewIDScheme(name, pattern)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func (s *IDScheme) orDefault() *IDScheme {
+	if s == nil {
+		return DefaultIDScheme
+	}
+	return s
+}
+
+// String returns the name of the scheme, or the regular expression of a custom scheme.
+func (s *IDScheme) String() string {
+	s = s.orDefault()
+	return cmp.Or(s.Name, s.Pattern)
+}
+
+// Parse returns the reviewer and comment number of the ID and reports whether the ID matches the scheme.
+func (s *IDScheme) Parse(id string) (reviewer, comment string, ok bool) {
+	re := s.orDefault().re
+	m := re.FindStringSubmatch(strings.TrimSpace(id))
+	if m == nil {
+		return "", "", false
+	}
+	reviewer = m[re.SubexpIndex("reviewer")]
+	if idx := re.SubexpIndex("comment"); idx >= 0 {
+		comment = m[idx]
+	}
+	return reviewer, comment, true
+}
+
+// ReviewerID returns the reviewer of the ID, or an empty string if the ID does not match the scheme.
+func (s *IDScheme) ReviewerID(id string) string {
+	reviewer, _, _ := s.Parse(id)
+	return reviewer
+}
+
+// WithReviewerColumn returns a copy of the scheme that takes the reviewer of a record from the column
+// with the given index, e.g., a column with the reviewer role, and only parses the ID if the cell is empty.
+// A negative index returns the scheme as is.
+func (s *IDScheme) WithReviewerColumn(col int) *IDScheme {
+	if col < 0 {
+		return s
+	}
+	res := *s.orDefault()
+	res.reviewerColumn = col + 1
+	return &res
+}
+
+// reviewerCell returns the trimmed cell of the reviewer column, or an empty string if there is none.
+func (s *IDScheme) reviewerCell(rec []string) string {
+	if col := s.orDefault().reviewerColumn - 1; col >= 0 && col < len(rec) {
+		return strings.TrimSpace(rec[col])
+	}
+	return ""
+}
+
+// RecordReviewer returns the reviewer of the record, whose first column holds the ID,
+// or an empty string for an empty record.
+func (s *IDScheme) RecordReviewer(rec []string) string {
+	if len(rec) < 1 {
+		return ""
+	}
+	return cmp.Or(s.reviewerCell(rec), s.ReviewerID(rec[0]))
+}
+
+// Reviewers returns the reviewers of the records, whose first column holds the ID,
+// in the order in which they first appear.
+func (s *IDScheme) Reviewers(records [][]string) []string {
+	var res []string
+	for _, rec := range records {
+		if len(rec) < 1 {
+			continue
+		}
+		if r := s.RecordReviewer(rec); !slices.Contains(res, r) {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// RecordReviewers returns the reviewer of each record, or an empty string for empty records.
+func (s *IDScheme) RecordReviewers(records [][]string) []string {
+	res := make([]string, len(records))
+	for i, rec := range records {
+		res[i] = s.RecordReviewer(rec)
+	}
+	return res
+}
+
+// Unmatched returns the indices of the records whose ID does not match the scheme.
+// Empty records and records with a reviewer from the reviewer column are skipped.
+func (s *IDScheme) Unmatched(records [][]string) []int {
+	var res []int
+	for i, rec := range records {
+		if len(rec) < 1 {
+			continue
+		}
+		if _, _, ok := s.Parse(rec[0]); !ok && s.reviewerCell(rec) == "" {
+			res = append(res, i)
+		}
+	}
+	return res
+}
+
+// Identifier reduces the text to ASCII letters and digits, e.g., to use a reviewer ID in color names.
+func Identifier(text string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, text)
+}
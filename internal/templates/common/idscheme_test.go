@@ -0,0 +1,121 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIDScheme_Parse(t *testing.T) {
+	tests := []struct {
+		scheme   string
+		id       string
+		reviewer string
+		comment  string
+		ok       bool
+	}{
+		{"", "Rev1.2", "Rev1", "2", true},
+		{"", "R5 C1", "R5", "C1", true},
+		{"", "", "", "", true},
+		{"rev-dot", "Rev1.12", "Rev1", "12", true},
+		{"rev-dot", "Rev1", "", "", false},
+		{"reviewer-comment", "AE-R2-C14", "AE-R2", "14", true},
+		{"reviewer-comment", "R3-C1", "R3", "1", true},
+		{"reviewer-comment", "AE-R2", "", "", false},
+		{"verbose", "Reviewer #2 / Comment 3", "Reviewer #2", "3", true},
+		{"verbose", "reviewer 10/comment 1", "reviewer 10", "1", true},
+		{"verbose", "Rev2.3", "", "", false},
+		{`^(?P<reviewer>[A-Z]+)(?P<comment>\d+)$`, "AB12", "AB", "12", true},
+		{`^(?P<reviewer>[A-Z]+)`, " AB12 ", "AB", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.scheme+"/"+tt.id, func(t *testing.T) {
+			s, err := ParseIDScheme(tt.scheme)
+			if err != nil {
+				t.Fatalf("ParseIDScheme(%q) unexpected error: %v", tt.scheme, err)
+			}
+			reviewer, comment, ok := s.Parse(tt.id)
+			if reviewer != tt.reviewer || comment != tt.comment || ok != tt.ok {
+				t.Errorf("Parse(%q) = %q, %q, %v; want %q, %q, %v", tt.id, reviewer, comment, ok, tt.reviewer, tt.comment, tt.ok)
+			}
+		})
+	}
+}
+
+func TestParseIDScheme_Invalid(t *testing.T) {
+	for _, scheme := range []string{`^(?P<reviewer>R\d+`, `^R(\d+)$`, "unknown"} {
+		if _, err := ParseIDScheme(scheme); err == nil {
+			t.Errorf("ParseIDScheme(%q) expected error", scheme)
+		}
+	}
+}
+
+func TestIDScheme_String(t *testing.T) {
+	var nilScheme *IDScheme
+	if got := nilScheme.String(); got != "default" {
+		t.Errorf("nil String() = %q; want %q", got, "default")
+	}
+	s, _ := ParseIDScheme("Verbose")
+	if got := s.String(); got != "verbose" {
+		t.Errorf("String() = %q; want %q", got, "verbose")
+	}
+	s, _ = ParseIDScheme(`^(?P<reviewer>R\d+)`)
+	if got := s.String(); got != `^(?P<reviewer>R\d+)` {
+		t.Errorf("String() = %q; want the pattern", got)
+	}
+}
+
+func TestIDScheme_Reviewers(t *testing.T) {
+	records := [][]string{
+		{"AE-R2-C1"},
+		{"AE-R1-C1"},
+		{},
+		{"AE-R2-C2"},
+		{"Editor"},
+		{"R1-C1"},
+	}
+	s, _ := ParseIDScheme("reviewer-comment")
+
+	// the prefix distinguishes the reviewers AE-R1 and R1
+	if got, want := s.Reviewers(records), []string{"AE-R2", "AE-R1", "", "R1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Reviewers() = %q; want %q", got, want)
+	}
+	if got, want := s.RecordReviewers(records), []string{"AE-R2", "AE-R1", "", "AE-R2", "", "R1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("RecordReviewers() = %q; want %q", got, want)
+	}
+	if got, want := s.Unmatched(records), []int{4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmatched() = %v; want %v", got, want)
+	}
+}
+
+func TestIDScheme_WithReviewerColumn(t *testing.T) {
+	records := [][]string{
+		{"1", "R2"},
+		{"Rev1.1", ""},
+		{"2", " R2 "},
+		{"x"},
+	}
+	s, _ := ParseIDScheme("rev-dot")
+	s = s.WithReviewerColumn(1)
+
+	if got, want := s.Reviewers(records), []string{"R2", "Rev1", ""}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Reviewers() = %q; want %q", got, want)
+	}
+	if got, want := s.RecordReviewers(records), []string{"R2", "Rev1", "R2", ""}; !reflect.DeepEqual(got, want) {
+		t.Errorf("RecordReviewers() = %q; want %q", got, want)
+	}
+	if got, want := s.Unmatched(records), []int{3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmatched() = %v; want %v", got, want)
+	}
+	if got := s.String(); got != "rev-dot" {
+		t.Errorf("String() = %q; want %q", got, "rev-dot")
+	}
+
+	var nilScheme *IDScheme
+	if got := nilScheme.WithReviewerColumn(-1); got != nil {
+		t.Errorf("WithReviewerColumn(-1) = %v; want the scheme as is", got)
+	}
+	if got := nilScheme.WithReviewerColumn(1).RecordReviewer([]string{"Rev3.1", ""}); got != "Rev3" {
+		t.Errorf("RecordReviewer() = %q; want %q", got, "Rev3")
+	}
+}
@@ -0,0 +1,64 @@
+package common
+
+import "strings"
+
+// Options configures how a template renders the rejoinder.
+type Options struct {
+	Meta Metadata
+	// AppendixRounds moves all but the last review round into an appendix,
+	// if several rounds are rendered into one document.
+	AppendixRounds bool
+	// CellMarkup converts Markdown-like markup in cells, e.g., lists, **bold**, and [links](url),
+	// to the markup of the template instead of escaping it.
+	CellMarkup bool
+	// Escaping selects whether inline math and raw blocks in cells are passed through.
+	Escaping Escaping
+	// UnicodeFallbacks replaces characters that the template cannot represent, e.g., emoji in LaTeX.
+	// Templates without such limits ignore it.
+	UnicodeFallbacks map[rune]string
+	// IDScheme extracts the reviewer from comment IDs. Nil selects the default scheme.
+	IDScheme *IDScheme
+	// Palette colors the responses of each reviewer. The zero value selects the default palette.
+	Palette Palette
+	// Colors overrides the palette color of individual reviewers, keyed by reviewer ID.
+	Colors map[string]Color
+	// TableOfContents adds a table of contents of the reviewer sections.
+	TableOfContents bool
+	// Template replaces the built-in template of the format. Nil selects the built-in template.
+	Template *CustomTemplate
+}
+
+// Metadata holds information about the paper that is printed in the rejoinder.
+// Empty fields are replaced by placeholders in the generated document.
+type Metadata struct {
+	Title        string
+	ManuscriptID string
+	Authors      []string
+	Editor       string
+	Venue        string
+	CoverLetter  string
+	KeyChanges   []string
+}
+
+// AuthorNames returns the comma-separated list of authors.
+func (m Metadata) AuthorNames() string {
+	return strings.Join(m.Authors, ", ")
+}
+
+// Escaped returns a copy of the metadata where all fields are escaped with the given function.
+func (m Metadata) Escaped(escape func(string) string) Metadata {
+	res := Metadata{
+		Title:        escape(m.Title),
+		ManuscriptID: escape(m.ManuscriptID),
+		Editor:       escape(m.Editor),
+		Venue:        escape(m.Venue),
+		CoverLetter:  escape(m.CoverLetter),
+	}
+	for _, a := range m.Authors {
+		res.Authors = append(res.Authors, escape(a))
+	}
+	for _, c := range m.KeyChanges {
+		res.KeyChanges = append(res.KeyChanges, escape(c))
+	}
+	return res
+}
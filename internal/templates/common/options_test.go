@@ -0,0 +1,58 @@
+package common
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMetadata_AuthorNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		authors  []string
+		expected string
+	}{
+		{"no authors", nil, ""},
+		{"single author", []string{"Andreas Bauer"}, "Andreas Bauer"},
+		{"multiple authors", []string{"A", "B", "C"}, "A, B, C"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Metadata{Authors: tt.authors}.AuthorNames()
+			if got != tt.expected {
+				t.Errorf("AuthorNames() = %q; want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMetadata_Escaped(t *testing.T) {
+	meta := Metadata{
+		Title:        "a_b",
+		ManuscriptID: "ID_1",
+		Authors:      []string{"x_y"},
+		Editor:       "e_f",
+		Venue:        "v_w",
+		CoverLetter:  "c_d",
+		KeyChanges:   []string{"k_1", "k_2"},
+	}
+	escape := func(s string) string { return strings.ReplaceAll(s, "_", "\\_") }
+
+	got := meta.Escaped(escape)
+	want := Metadata{
+		Title:        "a\\_b",
+		ManuscriptID: "ID\\_1",
+		Authors:      []string{"x\\_y"},
+		Editor:       "e\\_f",
+		Venue:        "v\\_w",
+		CoverLetter:  "c\\_d",
+		KeyChanges:   []string{"k\\_1", "k\\_2"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Escaped() = %+v; want %+v", got, want)
+	}
+	if meta.Title != "a_b" {
+		t.Errorf("Escaped() modified the original metadata")
+	}
+}
@@ -0,0 +1,147 @@
+package common
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Color is an RGB color in hexadecimal notation, e.g., "#E69F00".
+type Color string
+
+// ParseColor parses a color in the notation #RRGGBB or #RGB. The leading # is optional.
+func ParseColor(s string) (Color, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 || strings.Trim(strings.ToLower(hex), "0123456789abcdef") != "" {
+		return "", fmt.Errorf("color %q is not in the notation #RRGGBB", s)
+	}
+	return Color("#" + strings.ToUpper(hex)), nil
+}
+
+// Hex returns the hexadecimal digits of the color without the leading #, e.g., for LaTeX's HTML color model.
+func (c Color) Hex() string {
+	return strings.TrimPrefix(string(c), "#")
+}
+
+// DefaultColor is the color of responses without a reviewer. It corresponds to black!15!white in LaTeX.
+const DefaultColor Color = "#D9D9D9"
+
+// Palette assigns distinct colors to the reviewers in the order in which they appear.
+// All colors are light enough for black text.
+type Palette struct {
+	Name   string
+	Colors []Color
+}
+
+var palettes = []Palette{
+	// Okabe-Ito palette mixed 50% with white
+	{"colorblind", []Color{"#F2CF80", "#ABDAF4", "#80CFB9", "#F8F2A1", "#80B9D9", "#EAAF80", "#E6BCD3"}},
+	// ColorBrewer Pastel1
+	{"pastel", []Color{"#FBB4AE", "#B3CDE3", "#CCEBC5", "#DECBE4", "#FED9A6", "#FFFFCC", "#E5D8BD", "#FDDAEC"}},
+	// gray levels that remain distinguishable in black-and-white print
+	{"grayscale", []Color{"#D9D9D9", "#A6A6A6", "#F2F2F2", "#BFBFBF", "#8C8C8C", "#E6E6E6"}},
+}
+
+// PaletteNames returns the names of all palettes. The first one is the default.
+func PaletteNames() []string {
+	res := make([]string, len(palettes))
+	for i, p := range palettes {
+		res[i] = p.Name
+	}
+	return res
+}
+
+// ParsePalette returns the palette with the given name. An empty name selects the default palette.
+func ParsePalette(name string) (Palette, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return palettes[0], nil
+	}
+	for _, p := range palettes {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return palettes[0], fmt.Errorf("palette %q is not supported, choose one of %v", name, PaletteNames())
+}
+
+// Color returns the color of the i-th reviewer. The colors repeat if there are more reviewers than colors.
+// The zero palette is the default palette.
+func (p Palette) Color(i int) Color {
+	if len(p.Colors) == 0 {
+		p = palettes[0]
+	}
+	return p.Colors[i%len(p.Colors)]
+}
+
+// ReviewerColors returns the color of each reviewer: the override of the options, if any,
+// or otherwise the next color of the palette. IDs without reviewer get the default color.
+func (o Options) ReviewerColors(reviewers []string) []Color {
+	res := make([]Color, len(reviewers))
+	next := 0
+	for i, r := range reviewers {
+		if r == "" {
+			res[i] = DefaultColor
+			continue
+		}
+		if c, ok := o.Colors[r]; ok {
+			res[i] = c
+			continue
+		}
+		res[i] = o.Palette.Color(next)
+		next++
+	}
+	return res
+}
+
+// ReviewerColor is the color of a reviewer, whose ID is reduced to an identifier for color names.
+type ReviewerColor struct {
+	ID    string
+	Color Color
+}
+
+// ColorDefs returns the colors of the reviewers for the color definitions of a template, see ColorIDs.
+// Reviewers whose ID has no letters or digits are skipped and get the default color.
+func (o Options) ColorDefs(reviewers []string) []ReviewerColor {
+	var res []ReviewerColor
+	ids := ColorIDs(reviewers)
+	for i, c := range o.ReviewerColors(reviewers) {
+		if ids[i] != "" {
+			res = append(res, ReviewerColor{ID: ids[i], Color: c})
+		}
+	}
+	return res
+}
+
+// ColorIDs returns the identifier of each reviewer for color names, see Identifier.
+// Reviewers that differ only in other characters, e.g., R-1 and R1, get a numeric suffix,
+// e.g., R1 and R12, that no other reviewer uses, so that each reviewer keeps its own color.
+// The identifier is empty for reviewers without letters or digits.
+func ColorIDs(reviewers []string) []string {
+	res := make([]string, len(reviewers))
+	taken := make(map[string]bool)
+	for i, r := range reviewers {
+		res[i] = Identifier(r)
+		taken[res[i]] = true
+	}
+	used := make(map[string]bool)
+	for i, id := range res {
+		if id == "" {
+			continue
+		}
+		if used[id] {
+			n := 2
+			for taken[id+strconv.Itoa(n)] {
+				n++
+			}
+			id += strconv.Itoa(n)
+			res[i] = id
+			taken[id] = true
+		}
+		used[id] = true
+	}
+	return res
+}
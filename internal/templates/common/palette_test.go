@@ -0,0 +1,107 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Color
+		wantErr  bool
+	}{
+		{"#e69f00", "#E69F00", false},
+		{"E69F00", "#E69F00", false},
+		{" #abc ", "#AABBCC", false},
+		{"#12345", "", true},
+		{"#GGGGGG", "", true},
+		{"red", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseColor(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseColor(%q) error = %v; wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseColor(%q) = %q; want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParsePalette(t *testing.T) {
+	for _, name := range PaletteNames() {
+		p, err := ParsePalette(name)
+		if err != nil || p.Name != name {
+			t.Errorf("ParsePalette(%q) = %v, %v", name, p.Name, err)
+		}
+	}
+	if p, _ := ParsePalette(""); p.Name != "colorblind" {
+		t.Errorf("ParsePalette(\"\") = %q; want the default palette", p.Name)
+	}
+	if _, err := ParsePalette("neon"); err == nil {
+		t.Errorf("ParsePalette(\"neon\") expected error")
+	}
+}
+
+func TestPalette_DistinctColors(t *testing.T) {
+	for _, name := range PaletteNames() {
+		p, _ := ParsePalette(name)
+		seen := make(map[Color]bool)
+		for _, c := range p.Colors {
+			if _, err := ParseColor(string(c)); err != nil {
+				t.Errorf("palette %s: %v", name, err)
+			}
+			if seen[c] {
+				t.Errorf("palette %s: color %s is not distinct", name, c)
+			}
+			seen[c] = true
+		}
+	}
+}
+
+func TestOptions_ReviewerColors(t *testing.T) {
+	pastel, _ := ParsePalette("pastel")
+	opts := Options{
+		Palette: pastel,
+		Colors:  map[string]Color{"Rev2": "#FF0000"},
+	}
+
+	got := opts.ReviewerColors([]string{"Rev1", "Rev2", "", "Rev3"})
+	want := []Color{pastel.Colors[0], "#FF0000", DefaultColor, pastel.Colors[1]}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReviewerColors() = %v; want %v", got, want)
+	}
+
+	// colors repeat when the palette is exhausted
+	var zero Options
+	reviewers := make([]string, len(palettes[0].Colors)+1)
+	for i := range reviewers {
+		reviewers[i] = string(rune('A' + i))
+	}
+	colors := zero.ReviewerColors(reviewers)
+	if colors[len(colors)-1] != colors[0] {
+		t.Errorf("ReviewerColors() last = %v; want %v", colors[len(colors)-1], colors[0])
+	}
+}
+
+func TestOptions_ColorDefs(t *testing.T) {
+	opts := Options{Colors: map[string]Color{"Reviewer #2": "#FF0000"}}
+	got := opts.ColorDefs([]string{"Reviewer #2", "", "R_1", "R1"})
+	want := []ReviewerColor{{"Reviewer2", "#FF0000"}, {"R1", palettes[0].Colors[0]}, {"R12", palettes[0].Colors[1]}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ColorDefs() = %v; want %v", got, want)
+	}
+}
+
+func TestColorIDs(t *testing.T) {
+	got := ColorIDs([]string{"R-1", "R1", "Rev 2", "Rev2", "R12", "R 1", "#"})
+	want := []string{"R1", "R13", "Rev2", "Rev22", "R12", "R14", ""}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ColorIDs() = %q; want %q", got, want)
+	}
+}
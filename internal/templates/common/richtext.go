@@ -0,0 +1,16 @@
+package common
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SplitSpace splits the text into leading whitespace, the trimmed text, and trailing whitespace,
+// e.g., to place markup of a formatted run around the text only.
+func SplitSpace(text string) (lead, trimmed, trail string) {
+	trimmed = strings.TrimLeftFunc(text, unicode.IsSpace)
+	lead = text[:len(text)-len(trimmed)]
+	trimmed = strings.TrimRightFunc(trimmed, unicode.IsSpace)
+	trail = text[len(lead)+len(trimmed):]
+	return lead, trimmed, trail
+}
@@ -0,0 +1,25 @@
+package common
+
+import "testing"
+
+func TestSplitSpace(t *testing.T) {
+	tests := []struct {
+		input                  string
+		lead, trimmed, trailer string
+	}{
+		{"bold", "", "bold", ""},
+		{" bold ", " ", "bold", " "},
+		{"\n two words\t", "\n ", "two words", "\t"},
+		{"   ", "   ", "", ""},
+		{"", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			lead, trimmed, trail := SplitSpace(tt.input)
+			if lead != tt.lead || trimmed != tt.trimmed || trail != tt.trailer {
+				t.Errorf("SplitSpace(%q) = %q, %q, %q; want %q, %q, %q", tt.input, lead, trimmed, trail, tt.lead, tt.trimmed, tt.trailer)
+			}
+		})
+	}
+}
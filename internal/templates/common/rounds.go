@@ -0,0 +1,21 @@
+package common
+
+// RoundOrder returns the indices of n review rounds in the order they are rendered
+// and the position in that order where the appendix starts.
+// With appendix set, the last round comes first and all earlier rounds follow in the appendix.
+// Otherwise, the rounds keep their order and the appendix position is n, i.e., there is no appendix.
+func RoundOrder(n int, appendix bool) ([]int, int) {
+	order := make([]int, 0, n)
+	if !appendix || n < 2 {
+		for i := range n {
+			order = append(order, i)
+		}
+		return order, n
+	}
+
+	order = append(order, n-1)
+	for i := range n - 1 {
+		order = append(order, i)
+	}
+	return order, 1
+}
@@ -0,0 +1,30 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRoundOrder(t *testing.T) {
+	tests := []struct {
+		name          string
+		n             int
+		appendix      bool
+		expected      []int
+		expectedStart int
+	}{
+		{"no rounds", 0, true, []int{}, 0},
+		{"single round with appendix", 1, true, []int{0}, 1},
+		{"keep order", 3, false, []int{0, 1, 2}, 3},
+		{"last round first", 3, true, []int{2, 0, 1}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, start := RoundOrder(tt.n, tt.appendix)
+			if !reflect.DeepEqual(got, tt.expected) || start != tt.expectedStart {
+				t.Errorf("RoundOrder(%d, %v) = %v, %d; want %v, %d", tt.n, tt.appendix, got, start, tt.expected, tt.expectedStart)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package common
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ReviewerGroup holds the indices of the records of one reviewer.
+type ReviewerGroup struct {
+	Reviewer string
+	Records  []int
+}
+
+// Group groups the indices of the records by reviewer, in the order in which the reviewers first appear.
+// Empty records are skipped.
+func (s *IDScheme) Group(records [][]string) []ReviewerGroup {
+	var res []ReviewerGroup
+	pos := make(map[string]int)
+	for i, rec := range records {
+		if len(rec) < 1 {
+			continue
+		}
+		r := s.RecordReviewer(rec)
+		p, ok := pos[r]
+		if !ok {
+			p = len(res)
+			pos[r] = p
+			res = append(res, ReviewerGroup{Reviewer: r})
+		}
+		res[p].Records = append(res[p].Records, i)
+	}
+	return res
+}
+
+var (
+	reviewerNumber = regexp.MustCompile(`(?i)^(?:r|rev|reviewer|referee)\s*#?\s*(\d+)$`)
+	editorName     = regexp.MustCompile(`(?i)^(?:e|ed|editor)$`)
+	assocEditor    = regexp.MustCompile(`(?i)^(?:ae|associate\s*editor)$`)
+)
+
+// ReviewerName returns the heading of the reviewer's section, e.g., "Reviewer 1" for Rev1 or "Editor" for ED.
+// Unknown reviewers keep their ID.
+func ReviewerName(reviewer string) string {
+	reviewer = strings.TrimSpace(reviewer)
+	switch {
+	case reviewer == "":
+		return "Other Comments"
+	case editorName.MatchString(reviewer):
+		return "Editor"
+	case assocEditor.MatchString(reviewer):
+		return "Associate Editor"
+	}
+	if m := reviewerNumber.FindStringSubmatch(reviewer); m != nil {
+		return "Reviewer " + m[1]
+	}
+	return reviewer
+}
+
+// Label reduces the ID to the characters that LaTeX and Typst accept in labels:
+// ASCII letters, digits, and . : - _
+func Label(id string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune(".:-_", r) {
+			return r
+		}
+		return -1
+	}, id)
+}
+
+// Labels returns a unique label for the ID of each record, or an empty string for empty records.
+// IDs without any valid character are labeled "response".
+// The prefix, e.g., of a review round, is separated by a colon. Duplicate IDs get a numeric suffix.
+func Labels(prefix string, records [][]string) []string {
+	res := make([]string, len(records))
+	used := make(map[string]bool)
+	for i, rec := range records {
+		if len(rec) < 1 {
+			continue
+		}
+		label := cmp.Or(Label(rec[0]), "response")
+		if prefix != "" {
+			label = prefix + ":" + label
+		}
+		unique := label
+		for n := 2; used[unique]; n++ {
+			unique = fmt.Sprintf("%s-%d", label, n)
+		}
+		used[unique] = true
+		res[i] = unique
+	}
+	return res
+}
@@ -0,0 +1,72 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIDScheme_Group(t *testing.T) {
+	records := [][]string{
+		{"Rev1.1"},
+		{"Rev2.1"},
+		{},
+		{"Rev1.2"},
+		{""},
+	}
+	got := DefaultIDScheme.Group(records)
+	want := []ReviewerGroup{
+		{Reviewer: "Rev1", Records: []int{0, 3}},
+		{Reviewer: "Rev2", Records: []int{1}},
+		{Reviewer: "", Records: []int{4}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Group() = %+v; want %+v", got, want)
+	}
+}
+
+func TestReviewerName(t *testing.T) {
+	tests := []struct {
+		reviewer string
+		expected string
+	}{
+		{"Rev1", "Reviewer 1"},
+		{"R2", "Reviewer 2"},
+		{"Reviewer #3", "Reviewer 3"},
+		{"referee 4", "Reviewer 4"},
+		{"ED", "Editor"},
+		{"Editor", "Editor"},
+		{"AE", "Associate Editor"},
+		{"Meta", "Meta"},
+		{"", "Other Comments"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.reviewer, func(t *testing.T) {
+			if got := ReviewerName(tt.reviewer); got != tt.expected {
+				t.Errorf("ReviewerName(%q) = %q; want %q", tt.reviewer, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLabels(t *testing.T) {
+	records := [][]string{
+		{"Rev1.3"},
+		{"Reviewer #2 / Comment 3"},
+		{},
+		{"Rev1.3"},
+		{"#"},
+		{"AE-R2-C14"},
+	}
+
+	got := Labels("", records)
+	want := []string{"Rev1.3", "Reviewer2Comment3", "", "Rev1.3-2", "response", "AE-R2-C14"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Labels() = %q; want %q", got, want)
+	}
+
+	got = Labels("Round2", records[:1])
+	if want := []string{"Round2:Rev1.3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Labels() with prefix = %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,186 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/andreas-bauer/rejoinderoo/internal/model"
+	"github.com/andreas-bauer/rejoinderoo/internal/templates/common"
+)
+
+// Docx renders a rejoinder as Word document (Office Open XML).
+type Docx struct{}
+
+type record struct {
+	Header string
+	Text   string
+}
+
+// response is the box of a comment; Comment is nil without a comment column.
+type response struct {
+	ID      string
+	Color   string
+	Comment *record
+	Records []record
+}
+
+type document struct {
+	Meta      common.Metadata
+	Responses []response
+}
+
+// defaultColor corresponds to black!15!white used by the LaTeX template.
+const defaultColor = "D9D9D9"
+
+//go:embed document.xml.tmpl
+var file string
+
+// Static package parts that do not depend on the rendered data.
+const (
+	contentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+  <Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+  <Default Extension="xml" ContentType="application/xml"/>
+  <Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>
+  <Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>
+</Types>`
+
+	rootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+  <Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>
+</Relationships>`
+
+	documentRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+  <Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+</Relationships>`
+
+	styles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:docDefaults>
+    <w:rPrDefault><w:rPr><w:rFonts w:ascii="Palatino Linotype" w:hAnsi="Palatino Linotype" w:cs="Palatino Linotype"/><w:sz w:val="22"/><w:szCs w:val="22"/></w:rPr></w:rPrDefault>
+    <w:pPrDefault><w:pPr><w:spacing w:after="120"/></w:pPr></w:pPrDefault>
+  </w:docDefaults>
+  <w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/></w:style>
+  <w:style w:type="paragraph" w:styleId="Title"><w:name w:val="Title"/><w:basedOn w:val="Normal"/><w:pPr><w:jc w:val="center"/></w:pPr><w:rPr><w:sz w:val="32"/><w:szCs w:val="32"/></w:rPr></w:style>
+  <w:style w:type="paragraph" w:styleId="Subtitle"><w:name w:val="Subtitle"/><w:basedOn w:val="Normal"/><w:pPr><w:jc w:val="center"/></w:pPr></w:style>
+</w:styles>`
+)
+
+func NewDocxTemplate() *Docx {
+	return &Docx{}
+}
+
+// FileExtension returns the file extension for Word documents.
+func (d *Docx) FileExtension() string {
+	return ".docx"
+}
+
+// MIMEType is the media type of Word documents.
+const MIMEType = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+
+// Render writes the Word document package with the responses of the document to w.
+func (d *Docx) Render(ctx context.Context, w io.Writer, doc common.Document, opts common.Options) error {
+	if opts.Template != nil {
+		return fmt.Errorf("custom templates are not supported for DOCX")
+	}
+	td, err := doc.Single("DOCX")
+	if err != nil {
+		return err
+	}
+	data := createDoc(model.New(td, opts))
+
+	tmpl, err := template.New("docx").Funcs(template.FuncMap{"runs": runs}).Parse(file)
+	if err != nil {
+		return fmt.Errorf("parsing docx template: %w", err)
+	}
+
+	var body bytes.Buffer
+	err = tmpl.Execute(common.NewContextWriter(ctx, &body), data)
+	if err != nil {
+		return fmt.Errorf("executing docx template: %w", err)
+	}
+
+	parts := []struct {
+		name    string
+		content []byte
+	}{
+		{"[Content_Types].xml", []byte(contentTypes)},
+		{"_rels/.rels", []byte(rootRels)},
+		{"word/_rels/document.xml.rels", []byte(documentRels)},
+		{"word/styles.xml", []byte(styles)},
+		{"word/document.xml", body.Bytes()},
+	}
+
+	zw := zip.NewWriter(common.NewContextWriter(ctx, w))
+	for _, p := range parts {
+		pw, err := zw.Create(p.name)
+		if err != nil {
+			return err
+		}
+		if _, err := pw.Write(p.content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// createDoc converts the comments of the rejoinder to responses in the order of the input,
+// colored by their reviewer, with the comment and the details as records,
+// the same way as the LaTeX template does.
+func createDoc(rj model.Rejoinder) document {
+	colors := make(map[string]string)
+	for _, rev := range rj.Reviewers {
+		colors[rev.ID] = rev.Color.Hex()
+	}
+
+	comments := rj.Comments()
+	doc := document{
+		Meta:      rj.Meta,
+		Responses: make([]response, 0, len(comments)),
+	}
+	for _, c := range comments {
+		res := response{
+			ID:    c.ID.Text,
+			Color: defaultColor,
+		}
+		if color, ok := colors[c.Reviewer]; ok {
+			res.Color = color
+		}
+		if rj.Columns.HasComment {
+			res.Comment = &record{Header: c.Comment.Header, Text: c.Comment.Text}
+		}
+		for _, cell := range rj.Details(c) {
+			res.Records = append(res.Records, record{Header: cell.Header, Text: cell.Text})
+		}
+		doc.Responses = append(doc.Responses, res)
+	}
+	return doc
+}
+
+// runs converts text into WordprocessingML runs, escaping XML special
+// characters and turning line breaks into <w:br/> elements.
+func runs(text string, bold bool) string {
+	var sb strings.Builder
+	sb.WriteString("<w:r>")
+	if bold {
+		sb.WriteString("<w:rPr><w:b/></w:rPr>")
+	}
+	for i, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if i > 0 {
+			sb.WriteString("<w:br/>")
+		}
+		sb.WriteString(`<w:t xml:space="preserve">`)
+		xml.EscapeText(&sb, []byte(line))
+		sb.WriteString("</w:t>")
+	}
+	sb.WriteString("</w:r>")
+	return sb.String()
+}
@@ -0,0 +1,199 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/andreas-bauer/rejoinderoo/internal/model"
+	"github.com/andreas-bauer/rejoinderoo/internal/reader"
+	"github.com/andreas-bauer/rejoinderoo/internal/templates/common"
+)
+
+func TestCreateDoc(t *testing.T) {
+	headers := []string{"ID", "Comment", "Response", "Action"}
+	records := [][]string{
+		{"Rev1.1", "Some comment", "Some response", "Some action"},
+		{},
+		{"Rev2.1", "Another comment"},
+		{".1", "Without reviewer"},
+	}
+	opts := common.Options{Colors: map[string]common.Color{"Rev1": "#FF0000"}}
+
+	result := createDoc(model.New(&reader.TabularData{Headers: headers, Records: records}, opts)).Responses
+	if len(result) != 3 {
+		t.Fatalf("createDoc() responses length = %d; want 3", len(result))
+	}
+
+	first := result[0]
+	if first.ID != "Rev1.1" || first.Color != "FF0000" {
+		t.Errorf("createDoc() response 0 = %+v; want ID Rev1.1 with color FF0000", first)
+	}
+	if first.Comment == nil || *first.Comment != (record{Header: "Comment", Text: "Some comment"}) {
+		t.Errorf("createDoc() response 0 comment = %+v", first.Comment)
+	}
+	wantRecords := []record{
+		{Header: "Response", Text: "Some response"},
+		{Header: "Action", Text: "Some action"},
+	}
+	if len(first.Records) != len(wantRecords) {
+		t.Fatalf("createDoc() response 0 records length = %d; want %d", len(first.Records), len(wantRecords))
+	}
+	for i, rec := range first.Records {
+		if rec != wantRecords[i] {
+			t.Errorf("createDoc() response 0 record[%d] = %+v; want %+v", i, rec, wantRecords[i])
+		}
+	}
+
+	second := result[1]
+	if second.ID != "Rev2.1" || second.Color == first.Color {
+		t.Errorf("createDoc() response 1 = %+v; want ID Rev2.1 with a color of the palette", second)
+	}
+	if len(second.Records) != 2 || second.Records[0].Text != "" {
+		t.Errorf("createDoc() response 1 records = %+v; want two records with empty text", second.Records)
+	}
+
+	if third := result[2]; third.Color != defaultColor {
+		t.Errorf("createDoc() response 2 color = %q; want %q", third.Color, defaultColor)
+	}
+}
+
+func TestCreateDoc_WithoutComment(t *testing.T) {
+	td := &reader.TabularData{
+		Headers: []string{"ID", "Response"},
+		Records: [][]string{{"Rev1.1", "Some response"}},
+		Roles:   []reader.Role{reader.IDRole, reader.ResponseRole},
+	}
+	result := createDoc(model.New(td, common.Options{})).Responses
+	if len(result) != 1 || result[0].Comment != nil {
+		t.Fatalf("createDoc() responses = %+v; want one response without comment", result)
+	}
+
+	var out bytes.Buffer
+	if err := NewDocxTemplate().Render(context.Background(), &out, common.NewDocument(td), common.Options{}); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	// only the response has a header paragraph
+	if body := documentXML(t, out.Bytes()); strings.Count(body, `: </w:t>`) != 1 {
+		t.Errorf("document.xml contains %d header paragraphs; want 1 for the response", strings.Count(body, `: </w:t>`))
+	}
+}
+
+// documentXML returns the main part of the rendered Word document.
+func documentXML(t *testing.T, docx []byte) string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(docx), int64(len(docx)))
+	if err != nil {
+		t.Fatalf("Render() is not a valid zip archive: %v", err)
+	}
+	for _, f := range zr.File {
+		if f.Name != "word/document.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rc.Close()
+		content, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(content)
+	}
+	t.Fatal("Render() does not contain word/document.xml")
+	return ""
+}
+
+func TestRuns(t *testing.T) {
+	tests := []struct {
+		text     string
+		bold     bool
+		expected string
+	}{
+		{"", false, `<w:r><w:t xml:space="preserve"></w:t></w:r>`},
+		{"a & <b>", false, `<w:r><w:t xml:space="preserve">a &amp; &lt;b&gt;</w:t></w:r>`},
+		{"bold", true, `<w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">bold</w:t></w:r>`},
+		{"one\r\ntwo", false, `<w:r><w:t xml:space="preserve">one</w:t><w:br/><w:t xml:space="preserve">two</w:t></w:r>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got := runs(tt.text, tt.bold)
+			if got != tt.expected {
+				t.Errorf("runs(%q, %v) = %q; want %q", tt.text, tt.bold, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDocxFileExtension(t *testing.T) {
+	got := NewDocxTemplate().FileExtension()
+	want := ".docx"
+	if got != want {
+		t.Errorf("FileExtension() = %q; want %q", got, want)
+	}
+}
+
+func TestRender(t *testing.T) {
+	td := reader.TabularData{
+		Headers: []string{"ID", "Comment", "Response"},
+		Records: [][]string{
+			{"Rev1.1", "Comment with <xml> & \"quotes\"", "Response"},
+		},
+	}
+
+	var out bytes.Buffer
+	if err := NewDocxTemplate().Render(context.Background(), &out, common.NewDocument(&td), common.Options{}); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	if err != nil {
+		t.Fatalf("Render() is not a valid zip archive: %v", err)
+	}
+
+	wantParts := []string{"[Content_Types].xml", "_rels/.rels", "word/_rels/document.xml.rels", "word/styles.xml", "word/document.xml"}
+	if len(zr.File) != len(wantParts) {
+		t.Fatalf("Render() contains %d parts; want %d", len(zr.File), len(wantParts))
+	}
+	for i, f := range zr.File {
+		if f.Name != wantParts[i] {
+			t.Errorf("Render() part[%d] = %q; want %q", i, f.Name, wantParts[i])
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("opening %s: %v", f.Name, err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("reading %s: %v", f.Name, err)
+		}
+
+		if err := wellFormed(content); err != nil {
+			t.Errorf("part %s is not well-formed XML: %v", f.Name, err)
+		}
+		if f.Name == "word/document.xml" && !strings.Contains(string(content), `w:fill="F2CF80"`) {
+			t.Errorf("document.xml does not contain shaded reviewer box")
+		}
+	}
+}
+
+func wellFormed(content []byte) error {
+	dec := xml.NewDecoder(bytes.NewReader(content))
+	for {
+		_, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
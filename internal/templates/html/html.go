@@ -0,0 +1,147 @@
+package html
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/andreas-bauer/rejoinderoo/internal/model"
+	"github.com/andreas-bauer/rejoinderoo/internal/templates/common"
+)
+
+// HTML renders a rejoinder as self-contained HTML page with inline CSS.
+type HTML struct{}
+
+type record struct {
+	Header string
+	Text   string
+}
+
+type response struct {
+	ID      string
+	Anchor  string
+	Comment record
+	Records []record
+}
+
+// reviewer holds the responses to a reviewer; Name is the heading in the table of contents, e.g., "Reviewer 1".
+type reviewer struct {
+	ReviewerID string
+	Name       string
+	Class      string
+	Color      template.CSS
+	Responses  []response
+}
+
+type document struct {
+	Meta      common.Metadata
+	Reviewers []reviewer
+}
+
+//go:embed html.tmpl
+var file string
+
+func NewHTMLTemplate() *HTML {
+	return &HTML{}
+}
+
+// FileExtension returns the file extension for HTML templates.
+func (h *HTML) FileExtension() string {
+	return ".html"
+}
+
+// Builtin returns the source of the built-in HTML template, e.g., as a starting point for a custom template.
+func (h *HTML) Builtin() string {
+	return file
+}
+
+// Render writes the HTML document with the responses of the document to w.
+// Escaping is handled by html/template.
+func (h *HTML) Render(ctx context.Context, w io.Writer, d common.Document, opts common.Options) error {
+	td, err := d.Single("HTML")
+	if err != nil {
+		return err
+	}
+	doc := createDoc(model.New(td, opts))
+
+	name, text := opts.Template.Source("html", file)
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return fmt.Errorf("parsing html template: %w", err)
+	}
+
+	err = tmpl.Execute(common.NewContextWriter(ctx, w), doc)
+	if err != nil {
+		return fmt.Errorf("executing html template: %w", err)
+	}
+
+	return nil
+}
+
+// createDoc converts the rejoinder to the document of the template with the reviewers in their order
+// and their colors. Responses get unique anchors, even if their IDs differ only in punctuation.
+func createDoc(rj model.Rejoinder) document {
+	doc := document{
+		Meta:      rj.Meta,
+		Reviewers: make([]reviewer, len(rj.Reviewers)),
+	}
+	used := make(map[string]bool)
+	for i, rev := range rj.Reviewers {
+		doc.Reviewers[i] = reviewer{
+			ReviewerID: rev.ID,
+			Name:       rev.Name,
+			Class:      fmt.Sprintf("reviewer-%d", i+1),
+			Color:      template.CSS(rev.Color),
+		}
+		for _, c := range rev.Comments {
+			res := asDocResponse(rj, c)
+			res.Anchor = uniqueAnchor(res.Anchor, used)
+			doc.Reviewers[i].Responses = append(doc.Reviewers[i].Responses, res)
+		}
+	}
+	return doc
+}
+
+// asDocResponse converts a comment to a response with the comment and the details as records,
+// the same way as the LaTeX template does.
+func asDocResponse(rj model.Rejoinder, c model.Comment) response {
+	res := response{
+		ID:     c.ID.Text,
+		Anchor: anchor(c.ID.Text),
+	}
+	if rj.Columns.HasComment {
+		res.Comment = record{Header: c.Comment.Header, Text: c.Comment.Text}
+	}
+	for _, cell := range rj.Details(c) {
+		res.Records = append(res.Records, record{Header: cell.Header, Text: cell.Text})
+	}
+	return res
+}
+
+// uniqueAnchor returns the anchor, or the anchor with a numeric suffix if it is already used,
+// the same way as common.Labels does, and marks the result as used.
+func uniqueAnchor(anchor string, used map[string]bool) string {
+	unique := anchor
+	for n := 2; used[unique]; n++ {
+		unique = fmt.Sprintf("%s-%d", anchor, n)
+	}
+	used[unique] = true
+	return unique
+}
+
+// anchor converts an ID into a value that can be used as fragment identifier.
+// All characters except letters, digits, '-', '_' and '.' are replaced by '-'.
+func anchor(id string) string {
+	mapped := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '-'
+		}
+	}, strings.TrimSpace(id))
+	return "response-" + mapped
+}
@@ -0,0 +1,178 @@
+package html
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andreas-bauer/rejoinderoo/internal/model"
+	"github.com/andreas-bauer/rejoinderoo/internal/reader"
+	"github.com/andreas-bauer/rejoinderoo/internal/templates/common"
+)
+
+func TestCreateDoc(t *testing.T) {
+	headers := []string{"ID", "Comment", "Response", "Action"}
+	records := [][]string{
+		{"Rev1.1", "First comment", "First response", "First action"},
+		{"Rev2.1", "Second comment"},
+		{},
+		{"Rev1.2", "Third comment", "Third response"},
+	}
+
+	doc := createDoc(model.New(&reader.TabularData{Headers: headers, Records: records}, common.Options{}))
+
+	if len(doc.Reviewers) != 2 {
+		t.Fatalf("createDoc() reviewers length = %d; want 2", len(doc.Reviewers))
+	}
+
+	rev1 := doc.Reviewers[0]
+	if rev1.ReviewerID != "Rev1" || rev1.Class != "reviewer-1" || len(rev1.Responses) != 2 {
+		t.Fatalf("createDoc() first reviewer = %+v; want Rev1 with class reviewer-1 and 2 responses", rev1)
+	}
+
+	first := rev1.Responses[0]
+	if first.Comment != (record{Header: "Comment", Text: "First comment"}) {
+		t.Errorf("createDoc() Rev1.1 comment = %+v", first.Comment)
+	}
+	wantRecords := []record{
+		{Header: "Response", Text: "First response"},
+		{Header: "Action", Text: "First action"},
+	}
+	if len(first.Records) != len(wantRecords) {
+		t.Fatalf("createDoc() Rev1.1 records length = %d; want %d", len(first.Records), len(wantRecords))
+	}
+	for i, rec := range first.Records {
+		if rec != wantRecords[i] {
+			t.Errorf("createDoc() Rev1.1 record[%d] = %+v; want %+v", i, rec, wantRecords[i])
+		}
+	}
+
+	rev2 := doc.Reviewers[1]
+	if rev2.Class != "reviewer-2" || len(rev2.Responses) != 1 {
+		t.Errorf("createDoc() second reviewer = %+v; want class reviewer-2 with 1 response", rev2)
+	}
+}
+
+func TestCreateDoc_UniqueAnchors(t *testing.T) {
+	headers := []string{"ID", "Comment"}
+	records := [][]string{
+		{"R1.1 a", "first"},
+		{"R1.1-a", "second"},
+		{"R2.1", "third"},
+		{"R1.1:a", "fourth"},
+	}
+
+	doc := createDoc(model.New(&reader.TabularData{Headers: headers, Records: records}, common.Options{}))
+	var got []string
+	for _, rev := range doc.Reviewers {
+		for _, res := range rev.Responses {
+			got = append(got, res.Anchor)
+		}
+	}
+	want := []string{"response-R1.1-a", "response-R1.1-a-2", "response-R1.1-a-3", "response-R2.1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("createDoc() anchors = %q; want %q", got, want)
+	}
+}
+
+func TestAnchor(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Rev1.1", "response-Rev1.1"},
+		{"R1:2", "response-R1-2"},
+		{" R5 C1 ", "response-R5-C1"},
+		{"Reviewer #2", "response-Reviewer--2"},
+		{"", "response-"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := anchor(tt.input)
+			if got != tt.expected {
+				t.Errorf("anchor(%q) = %q; want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHTMLFileExtension(t *testing.T) {
+	got := NewHTMLTemplate().FileExtension()
+	want := ".html"
+	if got != want {
+		t.Errorf("FileExtension() = %q; want %q", got, want)
+	}
+}
+
+func TestRender(t *testing.T) {
+	td := reader.TabularData{
+		Headers: []string{"ID", "Comment", "Response"},
+		Records: [][]string{
+			{"Rev1.1", "<script>alert(1)</script>", "Done & dusted"},
+		},
+	}
+
+	out, err := renderString(common.NewDocument(&td), common.Options{})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	for _, want := range []string{
+		`<a href="#response-Rev1.1">Rev1.1</a>`,
+		`<article class="box" id="response-Rev1.1">`,
+		`&lt;script&gt;alert(1)&lt;/script&gt;`,
+		`Done &amp; dusted`,
+		`@media print`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Render() output does not contain %q", want)
+		}
+	}
+	if strings.Contains(out, "<script>") {
+		t.Errorf("Render() output contains unescaped script tag")
+	}
+}
+
+func TestRender_WithoutComment(t *testing.T) {
+	td := reader.TabularData{
+		Headers: []string{"ID", "Response"},
+		Records: [][]string{{"Rev1.1", "Done"}},
+		Roles:   []reader.Role{reader.IDRole, reader.ResponseRole},
+	}
+
+	out, err := renderString(common.NewDocument(&td), common.Options{})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if strings.Contains(out, `<div class="upper">`) {
+		t.Errorf("Render() output contains an empty comment")
+	}
+	if !strings.Contains(out, `<strong>Response:</strong>`) {
+		t.Errorf("Render() output does not contain the response")
+	}
+}
+
+// renderString renders the document with the html template into a string.
+func TestRender_ReviewerNames(t *testing.T) {
+	td := &reader.TabularData{
+		Headers: []string{"ID", "Comment", "Response"},
+		Records: [][]string{{"Rev1.1", "c1", "r1"}, {".1", "c2", "r2"}},
+	}
+	out, err := renderString(common.NewDocument(td), common.Options{})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	for _, want := range []string{"<li>\n          Reviewer 1\n", "<li>\n          Other Comments\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Render() output does not contain %q", want)
+		}
+	}
+}
+
+func renderString(doc common.Document, opts common.Options) (string, error) {
+	var b strings.Builder
+	err := NewHTMLTemplate().Render(context.Background(), &b, doc, opts)
+	return b.String(), err
+}
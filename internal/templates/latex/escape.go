@@ -0,0 +1,297 @@
+package latex
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/andreas-bauer/rejoinderoo/internal/templates/common"
+)
+
+// specials maps the LaTeX special characters to their escaped versions.
+var specials = map[rune]string{
+	'\\': `\textbackslash{}`,
+	'{':  `\{`,
+	'}':  `\}`,
+	'$':  `\$`,
+	'&':  `\&`,
+	'#':  `\#`,
+	'_':  `\_`,
+	'%':  `\%`,
+	'~':  `\textasciitilde{}`,
+	'^':  `\textasciicircum{}`,
+	'<':  `\textless{}`,
+	'>':  `\textgreater{}`,
+	'|':  `\textbar{}`,
+}
+
+// typography normalizes typographic characters, e.g., smart quotes and dashes
+// pasted from Word, to their LaTeX equivalents.
+var typography = map[rune]string{
+	'“':      "``",
+	'”':      "''",
+	'„':      ",,",
+	'‘':      "`",
+	'’':      "'",
+	'‚':      ",",
+	'–':      "--",
+	'—':      "---",
+	'−':      "-",
+	'…':      `\ldots{}`,
+	'•':      `\textbullet{}`,
+	'€':      `\euro{}`,
+	'\u00a0': "~",  // non-breaking space
+	'\u2009': `\,`, // thin space
+	'\u202f': `\,`, // narrow non-breaking space
+	'\u00ad': `\-`, // soft hyphen
+	'\u200b': "",   // zero-width space
+	'\ufeff': "",   // byte order mark
+}
+
+// DefaultFallbacks replaces common characters that inputenc cannot handle.
+// All other unsupported characters, e.g., emoji or CJK, are replaced by UnknownFallback.
+var DefaultFallbacks = map[rune]string{
+	'≤': `$\leq$`,
+	'≥': `$\geq$`,
+	'≠': `$\neq$`,
+	'≈': `$\approx$`,
+	'∞': `$\infty$`,
+	'→': `$\rightarrow$`,
+	'←': `$\leftarrow$`,
+	'↔': `$\leftrightarrow$`,
+	'⇒': `$\Rightarrow$`,
+	'α': `$\alpha$`,
+	'β': `$\beta$`,
+	'γ': `$\gamma$`,
+	'δ': `$\delta$`,
+	'λ': `$\lambda$`,
+	'μ': `$\mu$`,
+	'π': `$\pi$`,
+	'σ': `$\sigma$`,
+	'✓': "(yes)",
+	'✔': "(yes)",
+	'✗': "(no)",
+	'✘': "(no)",
+}
+
+// UnknownFallback replaces unsupported characters without fallback.
+const UnknownFallback = "?"
+
+// supported reports whether inputenc with T1 font encoding can typeset the character as is.
+func supported(r rune) bool {
+	switch {
+	case r == '\n' || r == '\t':
+		return true
+	case r >= 0x20 && r < 0x7f: // ASCII
+		return true
+	case r >= 0xa0 && r <= 0x17f: // Latin-1 Supplement and Latin Extended-A
+		return true
+	case r == common.PlaceholderOpen || r == common.PlaceholderClose:
+		return true
+	}
+	return false
+}
+
+// escapeWith escapes all LaTeX special characters, normalizes typographic characters,
+// and replaces unsupported characters with the given fallbacks, the default fallbacks,
+// or UnknownFallback, in this order. Control characters are removed.
+func escapeWith(input string, fallbacks map[rune]string) string {
+	var sb strings.Builder
+	sb.Grow(len(input))
+	for _, r := range input {
+		if s, ok := replacement(r, fallbacks); ok {
+			sb.WriteString(s)
+		} else if !unicode.IsControl(r) {
+			sb.WriteString(UnknownFallback)
+		}
+	}
+	return sb.String()
+}
+
+// replacement returns the LaTeX markup of the character and reports whether there is one,
+// i.e., whether the character is neither a control character nor replaced by UnknownFallback.
+func replacement(r rune, fallbacks map[rune]string) (string, bool) {
+	if s, ok := specials[r]; ok {
+		return s, true
+	} else if s, ok := typography[r]; ok {
+		return s, true
+	} else if supported(r) {
+		return string(r), true
+	} else if s, ok := fallbacks[r]; ok {
+		return s, true
+	} else if s, ok := DefaultFallbacks[r]; ok {
+		return s, true
+	}
+	return "", false
+}
+
+// unknownChars returns the distinct characters of the text that are replaced by UnknownFallback
+// in their order, see escapeWith.
+func unknownChars(text string, fallbacks map[rune]string) []rune {
+	var res []rune
+	for _, r := range text {
+		if _, ok := replacement(r, fallbacks); !ok && !unicode.IsControl(r) && !slices.Contains(res, r) {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// escape escapes special characters for LaTeX with the default fallbacks.
+func escape(input string) string {
+	return escapeWith(input, nil)
+}
+
+// textEscaper escapes text according to the escaping mode and fallbacks of the options.
+type textEscaper struct {
+	escaping  common.Escaping
+	fallbacks map[rune]string
+	refs      *common.CrossRefs
+}
+
+func newTextEscaper(opts common.Options) textEscaper {
+	return textEscaper{escaping: opts.Escaping, fallbacks: opts.UnicodeFallbacks}
+}
+
+// withRefs returns a copy of the escaper that links the given cross-references to their responses.
+func (e textEscaper) withRefs(refs *common.CrossRefs) textEscaper {
+	e.refs = refs
+	return e
+}
+
+// strict escapes all special characters regardless of the escaping mode.
+func (e textEscaper) strict(s string) string {
+	return escapeWith(s, e.fallbacks)
+}
+
+// escape escapes the text, passing through what the escaping mode allows.
+func (e textEscaper) escape(s string) string {
+	protected, restore := e.protect(s)
+	return restore(e.strict(protected))
+}
+
+// protect replaces what the escaping mode passes through, as well as citations and cross-references,
+// with placeholders, see common.Escaping.Protect.
+func (e textEscaper) protect(s string) (string, func(string) string) {
+	protected, restore := e.escaping.Protect(s, e.strict, commandLen)
+	protected, restoreCites := protectCitations(protected)
+	protected, restoreRefs := e.refs.Protect(protected, common.RefFormat{Escape: e.strict, Link: hyperref})
+	return protected, func(s string) string { return restore(restoreCites(restoreRefs(s))) }
+}
+
+// protectCitations replaces citations that follow a tie, e.g., ~\cite{key} or ~\citep[p.~3]{key},
+// with placeholders, so that they are passed through in all escaping modes. Citations whose
+// arguments contain special characters, e.g., ~\cite{a_b}, are escaped, see plainCitation.
+func protectCitations(text string) (string, func(string) string) {
+	if !strings.Contains(text, citation) {
+		return text, func(s string) string { return s }
+	}
+
+	var sb strings.Builder
+	var pairs []string
+	for i := 0; i < len(text); {
+		if n := commandLen(text[i:]); strings.HasPrefix(text[i:], citation) && plainCitation(text[i:i+n]) {
+			placeholder := fmt.Sprintf("%ccite%d%c", common.PlaceholderOpen, len(pairs)/2, common.PlaceholderClose)
+			pairs = append(pairs, placeholder, text[i:i+n])
+			sb.WriteString(placeholder)
+			i += n
+			continue
+		}
+		sb.WriteByte(text[i])
+		i++
+	}
+	return sb.String(), strings.NewReplacer(pairs...).Replace
+}
+
+// citation starts a citation that is passed through as is, see protectCitations.
+const citation = `~\cite`
+
+// plainCitation reports whether the citation command has arguments, e.g., ~\citep[p.~3]{doe2024},
+// whose keys and notes contain no special characters other than , space and ~.
+func plainCitation(cmd string) bool {
+	if !strings.HasSuffix(cmd, "}") {
+		return false
+	}
+	args := cmd[strings.IndexAny(cmd, "[{"):]
+	for _, r := range args {
+		switch {
+		case strings.ContainsRune("[]{}~, ", r):
+		case r == common.PlaceholderOpen || r == common.PlaceholderClose:
+			return false
+		case specials[r] != "" || !supported(r):
+			return false
+		}
+	}
+	return true
+}
+
+// hyperref links the text to the response with the given label.
+func hyperref(text, label string) string {
+	return `\hyperref[` + label + `]{` + text + `}`
+}
+
+// commandLen returns the length of a LaTeX command at the start of the text, or 0 if there is none,
+// so that math-aware escaping passes commands such as ~\cite{key} or \emph{text} through.
+// A command consists of its name and any number of optional [...] and mandatory {...} arguments;
+// an escaped special character, e.g., \%, counts as command as well.
+func commandLen(text string) int {
+	i := 0
+	if strings.HasPrefix(text, `~\`) {
+		i = 1 // tie before a citation
+	}
+	if i >= len(text) || text[i] != '\\' || i+1 >= len(text) {
+		return 0
+	}
+	i++
+	if strings.IndexByte(`%&_#{}$~^ ,`, text[i]) >= 0 {
+		return i + 1
+	}
+
+	start := i
+	for i < len(text) && isLetter(text[i]) {
+		i++
+	}
+	if i == start {
+		return 0
+	}
+	if i < len(text) && text[i] == '*' {
+		i++
+	}
+
+	for i < len(text) && (text[i] == '{' || text[i] == '[') {
+		n := groupLen(text[i:])
+		if n == 0 {
+			break
+		}
+		i += n
+	}
+	return i
+}
+
+// groupLen returns the length of the balanced {...} or [...] group at the start of the text, or 0.
+func groupLen(text string) int {
+	open, closing := text[0], byte('}')
+	if open == '[' {
+		closing = ']'
+	}
+	depth := 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++ // skip escaped character
+		case open:
+			depth++
+		case closing:
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return 0
+}
+
+func isLetter(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
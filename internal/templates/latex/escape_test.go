@@ -0,0 +1,162 @@
+package latex
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/andreas-bauer/rejoinderoo/internal/templates/common"
+)
+
+func TestEscapeWithFallbacks(t *testing.T) {
+	fallbacks := map[rune]string{
+		'✓': `\checkmark{}`,
+		'🎉': "(party)",
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"done ✓", `done \checkmark{}`},
+		{"🎉 and 🚀", "(party) and ?"},
+		{"x ≥ 1", `x $\geq$ 1`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := escapeWith(tt.input, fallbacks)
+			if got != tt.expected {
+				t.Errorf("escapeWith(%q) = %q; want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTextEscaper_StrictCitations(t *testing.T) {
+	esc := newTextEscaper(common.Options{})
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`see~\cite{key}`, `see~\cite{key}`},
+		{`as in~\citep[p.~3]{doe2024} & more`, `as in~\citep[p.~3]{doe2024} \& more`},
+		{`\cite{key} without tie`, `\textbackslash{}cite\{key\} without tie`},
+		{`~\cite{%}`, `\textasciitilde{}\textbackslash{}cite\{\%\}`},
+		{`~\citep[#]{a_b}`, `\textasciitilde{}\textbackslash{}citep[\#]\{a\_b\}`},
+		{`see~\cite{a_b#c}`, `see\textasciitilde{}\textbackslash{}cite\{a\_b\#c\}`},
+		{`~\cite alone`, `\textasciitilde{}\textbackslash{}cite alone`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := esc.escape(tt.input); got != tt.expected {
+				t.Errorf("escape(%q) = %q; want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestUnknownChars(t *testing.T) {
+	got := string(unknownChars("🎉 漢字 ✓ 🎉 ok\x00", map[rune]string{'字': "zi"}))
+	if want := "🎉漢"; got != want {
+		t.Errorf("unknownChars() = %q; want %q", got, want)
+	}
+}
+
+func TestCommandLen(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{`\cite{key} rest`, len(`\cite{key}`)},
+		{`~\cite[p.~3]{key}.`, len(`~\cite[p.~3]{key}`)},
+		{`\emph{a {nested} group}!`, len(`\emph{a {nested} group}`)},
+		{`\section*{Title}`, len(`\section*{Title}`)},
+		{`\ldots and more`, len(`\ldots`)},
+		{`\% of`, 2},
+		{`\emph{unclosed`, len(`\emph`)},
+		{`\\ line break`, 0},
+		{`\1`, 0},
+		{`~ tilde`, 0},
+		{`plain`, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := commandLen(tt.input); got != tt.expected {
+				t.Errorf("commandLen(%q) = %d; want %d", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func FuzzEscape(f *testing.F) {
+	for _, seed := range []string{
+		"100% sure", `Price is $5 & \cite{key}`, "{a} <b> |c| ~d^ #e_f",
+		"“Smart” ‘quotes’ – and — dashes…", "x ≤ 5 → done ✓", "Emoji 🎉 and CJK 漢字",
+		"control\x00\x1b chars\r\n", "\xff invalid UTF-8", "placeholder \ue0000\ue001",
+		`~\cite{%}`, `~\citep[#]{a_b}`,
+	} {
+		f.Add(seed)
+	}
+
+	esc := newTextEscaper(common.Options{})
+	f.Fuzz(func(t *testing.T, input string) {
+		got := esc.escape(input)
+		if err := compileSafe(got); err != nil {
+			t.Errorf("escape(%q) = %q is not compile-safe: %v", input, got, err)
+		}
+	})
+}
+
+// compileSafe checks that the text is a sequence of tokens that LaTeX with inputenc can typeset:
+// only supported characters, commands and escaped special characters, balanced braces and
+// math shifts, and no unescaped special characters.
+func compileSafe(text string) error {
+	depth := 0
+	math := false
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '\\':
+			if i+1 >= len(text) {
+				return fmt.Errorf("trailing backslash")
+			}
+			if strings.IndexByte(`{}$&#_%,-`, text[i+1]) >= 0 {
+				i++
+				continue
+			}
+			j := i + 1
+			for j < len(text) && isLetter(text[j]) {
+				j++
+			}
+			if j == i+1 {
+				return fmt.Errorf("invalid command at %d", i)
+			}
+			i = j - 1
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth < 0 {
+				return fmt.Errorf("unbalanced } at %d", i)
+			}
+		case c == '$':
+			math = !math
+		case strings.IndexByte("#%&_^", c) >= 0:
+			return fmt.Errorf("unescaped %q at %d", c, i)
+		}
+	}
+	if depth != 0 {
+		return fmt.Errorf("unbalanced {")
+	}
+	if math {
+		return fmt.Errorf("unclosed math")
+	}
+	for i, r := range text {
+		if !supported(r) || r == common.PlaceholderOpen || r == common.PlaceholderClose {
+			return fmt.Errorf("unsupported character %U at %d", r, i)
+		}
+	}
+	return nil
+}
@@ -1,14 +1,17 @@
 package latex
 
 import (
+	"context"
 	_ "embed"
 	"fmt"
+	"io"
 	"strings"
 	"text/template"
 
+	"github.com/andreas-bauer/rejoinderoo/internal/model"
 	"github.com/andreas-bauer/rejoinderoo/internal/reader"
+	"github.com/andreas-bauer/rejoinderoo/internal/templates/cellmarkup"
 	"github.com/andreas-bauer/rejoinderoo/internal/templates/common"
-	templates "github.com/andreas-bauer/rejoinderoo/internal/templates/common"
 )
 
 // Latex handles escaping special characters for LaTeX templates.
@@ -25,15 +28,41 @@ type record struct {
 }
 
 type response struct {
+	ID         string
+	Label      string
 	ReviewerID string
 	Records    []record
 }
 
+// section holds the responses to one reviewer.
+type section struct {
+	Name      string
+	Responses []response
+}
+
+// round holds the responses of one review round, rendered as its own section.
+// ColorPrefix keeps the reviewer colors and labels of different rounds apart.
+// The reviewer sections are nested below the section of a named round.
+// Comment and Details are the arguments of the response macro that are rendered
+// above and below the line of the response box; Comment is nil without a comment column.
+type round struct {
+	Name           string
+	ColorPrefix    string
+	StartAppendix  bool
+	Redefine       bool
+	Colors         []common.ReviewerColor
+	LenHeaders     int
+	Comment        *header
+	Details        []header
+	SectionCommand string
+	BookmarkLevel  int
+	Sections       []section
+}
+
 type document struct {
-	ReviewerIDs []string
-	LenHeaders  int
-	Headers     []header
-	Responses   []response
+	Meta   common.Metadata
+	TOC    bool
+	Rounds []round
 }
 
 func NewLatexTemplate() *Latex {
@@ -48,109 +77,265 @@ func (l *Latex) FileExtension() string {
 	return ".tex"
 }
 
-// Render processes the LaTeX template with the provided tabular data.
-func (l *Latex) Render(td reader.TabularData) (string, error) {
+// Builtin returns the source of the built-in LaTeX template, e.g., as a starting point for a custom template.
+func (l *Latex) Builtin() string {
+	return file
+}
 
-	escapeAllStrings(&td)
-	doc := createDoc(&td)
+// Render writes the LaTeX document with the responses of the document to w.
+// With several review rounds, the rounds are rendered in chronological order with a section per round.
+func (l *Latex) Render(ctx context.Context, w io.Writer, doc common.Document, opts common.Options) error {
+	if doc.Rounds != nil {
+		rounds, err := createRounds(*doc.Rounds, opts)
+		if err != nil {
+			return err
+		}
+		return render(ctx, w, rounds, opts)
+	}
+	td, err := doc.Single("LaTeX")
+	if err != nil {
+		return err
+	}
+	return render(ctx, w, []round{createRound(td, opts, "")}, opts)
+}
 
-	tmpl, err := template.New("latex").Parse(file)
+// Warnings reports the characters of the rendered columns that LaTeX cannot typeset and that have
+// no fallback, e.g., emoji or CJK, which are replaced by UnknownFallback.
+// Text that the escaping mode passes through is not checked.
+func (l *Latex) Warnings(td *reader.TabularData, opts common.Options) []string {
+	esc := newTextEscaper(opts)
+	unknown := func(text string) string {
+		protected, _ := esc.protect(text)
+		return string(unknownChars(protected, opts.UnicodeFallbacks))
+	}
 
-	if err != nil {
-		fmt.Println(err.Error())
-		panic(err)
+	layout := td.Layout()
+	if layout.ID < 0 {
+		return nil
 	}
+	cols := append([]int{layout.ID}, layout.Fields()...)
+	var res []string
+	for _, col := range cols {
+		if chars := unknown(td.Headers[col]); chars != "" {
+			res = append(res, fmt.Sprintf("column %q contains characters %q that LaTeX cannot typeset, they are replaced by %q", td.Headers[col], chars, UnknownFallback))
+		}
+	}
+	for i, rec := range td.Records {
+		var text strings.Builder
+		for _, col := range cols {
+			if col < len(rec) {
+				text.WriteString(rec[col])
+			}
+		}
+		if chars := unknown(text.String()); chars != "" {
+			res = append(res, fmt.Sprintf("row %d contains characters %q that LaTeX cannot typeset, they are replaced by %q", td.Row(i), chars, UnknownFallback))
+		}
+	}
+	return res
+}
 
-	var result strings.Builder
-	err = tmpl.Execute(&result, doc)
-	if err != nil {
-		fmt.Println(err.Error())
-		panic(err)
+// createRounds creates a section per review round. The sheets of the workbook are the review rounds
+// in chronological order.
+func createRounds(wb reader.Workbook, opts common.Options) ([]round, error) {
+	if len(wb.Sheets) == 0 {
+		return nil, fmt.Errorf("workbook does not contain any review rounds")
 	}
 
-	return result.String(), nil
+	order, appendixStart := common.RoundOrder(len(wb.Sheets), opts.AppendixRounds)
+	rounds := make([]round, len(order))
+	for pos, idx := range order {
+		sheet := wb.Sheets[idx]
+		r := createRound(sheet.Data, opts, fmt.Sprintf("Round%d", idx+1))
+		r.Name = newTextEscaper(opts).strict(sheet.Name)
+		r.SectionCommand = "subsection"
+		r.BookmarkLevel = 3
+		r.StartAppendix = pos == appendixStart
+		r.Redefine = pos > 0
+		rounds[pos] = r
+	}
+	return rounds, nil
 }
 
-func createDoc(td *reader.TabularData) document {
-	allRevIDs := common.ExtractReviewers(td.Records)
-	headers := asDocHeaders(td.Headers)
-	responses := asDocResponses(td.Headers, td.Records)
+// render writes the rounds with the template of the options, or the built-in one, to w.
+// Rendering stops with the error of the context once it is done.
+func render(ctx context.Context, w io.Writer, rounds []round, opts common.Options) error {
+	doc := document{
+		Meta:   opts.Meta.Escaped(newTextEscaper(opts).escape),
+		TOC:    opts.TableOfContents,
+		Rounds: rounds,
+	}
 
-	return document{
-		ReviewerIDs: allRevIDs,
-		LenHeaders:  len(td.Headers) + 1, // because of Latex counting
-		Headers:     headers,
-		Responses:   responses,
+	name, text := opts.Template.Source("latex", file)
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return fmt.Errorf("parsing latex template: %w", err)
 	}
+
+	err = tmpl.Execute(common.NewContextWriter(ctx, w), doc)
+	if err != nil {
+		return fmt.Errorf("executing latex template: %w", err)
+	}
+
+	return nil
 }
 
-func escapeAllStrings(td *reader.TabularData) {
-	for i, h := range td.Headers {
-		td.Headers[i] = escape(h)
+// createRound groups the responses of the tabular data into a section per reviewer.
+// The prefix keeps the colors and labels of the round apart from those of other rounds.
+func createRound(td *reader.TabularData, opts common.Options, prefix string) round {
+	rj := model.New(td, opts)
+	// labels and cross-references are derived before escaping, which could break the ID scheme
+	labels := common.Labels(prefix, td.Records)
+	refs := common.NewCrossRefs(td.Records, labels, model.IDScheme(td, opts.IDScheme))
+
+	esc := newTextEscaper(opts)
+	// the response macro takes the color, the ID, the comment, and the details in this order
+	names := []string{esc.escape(rj.Columns.ID)}
+	if rj.Columns.HasComment {
+		names = append(names, esc.escape(rj.Columns.Comment))
+	}
+	for _, h := range rj.Columns.Details {
+		names = append(names, esc.escape(h))
+	}
+	headers := asDocHeaders(names)
+	var comment *header
+	details := headers[1:]
+	if rj.Columns.HasComment {
+		comment, details = &headers[1], headers[2:]
 	}
 
-	for i, rec := range td.Records {
-		for j, r := range rec {
-			td.Records[i][j] = escape(r)
+	sections := make([]section, len(rj.Reviewers))
+	for i, rev := range rj.Reviewers {
+		sections[i].Name = esc.strict(rev.Name)
+		for _, c := range rev.Comments {
+			res := asDocResponse(rj, c, esc, refs, opts.CellMarkup)
+			res.Label = labels[c.Index]
+			sections[i].Responses = append(sections[i].Responses, res)
 		}
 	}
+
+	return round{
+		ColorPrefix:    prefix,
+		Colors:         opts.ColorDefs(rj.ReviewerIDs()),
+		LenHeaders:     len(headers) + 1, // because of Latex counting
+		Comment:        comment,
+		Details:        details,
+		SectionCommand: "section",
+		BookmarkLevel:  2,
+		Sections:       sections,
+	}
 }
 
-// escape escapes special characters for LaTeX.
-func escape(input string) string {
-	// Replace LaTeX special characters with their escaped versions.
-	replacer := strings.NewReplacer(
-		// keep as is
-		"~\\cite", "~\\cite",
-		// modify
-		"$", "\\$",
-		"&", "\\&",
-		"#", "\\#",
-		"_", "\\_",
-		"%", "\\%",
-		"~", "\\textasciitilde{}",
-		"^", "\\textasciicircum{}",
-	)
-	return replacer.Replace(input)
-}
-
-// asDocHeaders converts a slice of strings to a slice of Header structs
-func asDocHeaders(headers []string) []header {
-	var res = make([]header, len(headers))
-	for idx, h := range headers {
-		header := &header{
-			Name: h,
-			Idx:  idx + 2, // Start from 2 to account for RevID and Latex counting
-		}
-		res[idx] = *header
+// asDocResponse converts a comment to a response with a record for the ID, the comment, and the details.
+// References to the IDs of other responses are linked in all records but the ID.
+func asDocResponse(rj model.Rejoinder, c model.Comment, esc textEscaper, refs *common.CrossRefs, markup bool) response {
+	id := escapeCell(c.ID, esc, markup)
+	res := response{
+		ID:         id,
+		ReviewerID: rj.ColorID(c.Reviewer),
+		Records:    []record{{Header: esc.escape(c.ID.Header), Text: id}},
+	}
+	for _, cell := range rj.Fields(c) {
+		res.Records = append(res.Records, record{
+			Header: esc.escape(cell.Header),
+			Text:   escapeCell(cell, esc.withRefs(refs.Of(c.Reviewer)), markup),
+		})
 	}
 	return res
 }
 
-// asDocResponses converts a slice of strings to a slice of Response structs
-func asDocResponses(headers []string, records [][]string) []response {
-	var res = make([]response, len(records))
-	for idx, rec := range records {
-		if len(rec) < 1 {
+// escapeCell escapes the text of a cell. Cells keep their rich text formatting or,
+// if markup is enabled, have their cell markup converted.
+func escapeCell(c model.Cell, esc textEscaper, markup bool) string {
+	switch {
+	case c.Rich != nil:
+		return richText(c.Rich, esc)
+	case markup:
+		text, restore := esc.protect(c.Text)
+		return restore(cellmarkup.Render(text, markupFormat(esc)))
+	default:
+		return esc.escape(c.Text)
+	}
+}
+
+// richText converts the formatted runs of a cell to escaped LaTeX markup.
+func richText(rt reader.RichText, e textEscaper) string {
+	esc := e.escape
+	var sb strings.Builder
+	for _, r := range rt {
+		lead, text, trail := common.SplitSpace(r.Text)
+		if text == "" || !r.Formatted() {
+			sb.WriteString(esc(r.Text))
 			continue
 		}
-		response := &response{
-			ReviewerID: templates.ExtractReviewerID(rec[0]),
-			Records:    make([]record, len(headers)),
+
+		if r.Link != "" {
+			// links cannot be nested, so cross-references are not linked within hyperlinks
+			text = e.withRefs(nil).escape(text)
+		} else {
+			text = esc(text)
+		}
+		if r.Bold {
+			text = `\textbf{` + text + `}`
 		}
-		for i, h := range headers {
-			var text string
-			if i < len(rec) {
-				text = rec[i]
+		if r.Italic {
+			text = `\emph{` + text + `}`
+		}
+		if r.Underline {
+			text = `\underline{` + text + `}`
+		}
+		if r.Link != "" {
+			text = `\href{` + escapeURL(r.Link) + `}{` + text + `}`
+		}
+		sb.WriteString(esc(lead) + text + esc(trail))
+	}
+	return sb.String()
+}
+
+// markupFormat converts cell markup to LaTeX.
+func markupFormat(e textEscaper) cellmarkup.Format {
+	return cellmarkup.Format{
+		Escape: e.strict,
+		Strong: func(content string) string { return `\textbf{` + content + `}` },
+		Emph:   func(content string) string { return `\emph{` + content + `}` },
+		Code:   func(code string) string { return `\texttt{` + e.strict(code) + `}` },
+		Link: func(url, content string) string {
+			return `\href{` + escapeURL(url) + `}{` + content + `}`
+		},
+		List: func(ordered bool, items []string) string {
+			env := "itemize"
+			if ordered {
+				env = "enumerate"
 			}
-			record := &record{
-				Header: h,
-				Text:   text,
+			var sb strings.Builder
+			sb.WriteString(`\begin{` + env + "}\n")
+			for _, item := range items {
+				sb.WriteString(`  \item ` + item + "\n")
 			}
-			response.Records[i] = *record
+			sb.WriteString(`\end{` + env + "}")
+			return sb.String()
+		},
+	}
+}
+
+// escapeURL escapes the characters of a URL that hyperref's \href does not accept as is.
+func escapeURL(url string) string {
+	return strings.NewReplacer(
+		"\\", "\\%5C",
+		"%", "\\%",
+		"#", "\\#",
+		"{", "\\%7B",
+		"}", "\\%7D",
+	).Replace(url)
+}
+
+// asDocHeaders converts the header names to a slice of Header structs
+func asDocHeaders(names []string) []header {
+	var res = make([]header, len(names))
+	for idx, name := range names {
+		res[idx] = header{
+			Name: name,
+			Idx:  idx + 2, // Start from 2 to account for the color and Latex counting
 		}
-		res[idx] = *response
 	}
 	return res
-
 }
@@ -1,10 +1,17 @@
 package latex
 
 import (
+	"context"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/andreas-bauer/rejoinderoo/internal/model"
+	"github.com/andreas-bauer/rejoinderoo/internal/reader"
+	"github.com/andreas-bauer/rejoinderoo/internal/templates/common"
 )
 
-func TestAsDocresponses(t *testing.T) {
+func TestAsDocResponse(t *testing.T) {
 	tests := []struct {
 		name     string
 		headers  []string
@@ -19,6 +26,7 @@ func TestAsDocresponses(t *testing.T) {
 			},
 			expected: []response{
 				{
+					ID:         "Rev1.1",
 					ReviewerID: "Rev1",
 					Records: []record{
 						{Header: "ID", Text: "Rev1.1"},
@@ -37,6 +45,7 @@ func TestAsDocresponses(t *testing.T) {
 			},
 			expected: []response{
 				{
+					ID:         "Rev2.1",
 					ReviewerID: "Rev2",
 					Records: []record{
 						{Header: "ID", Text: "Rev2.1"},
@@ -45,6 +54,7 @@ func TestAsDocresponses(t *testing.T) {
 					},
 				},
 				{
+					ID:         "Rev3.3",
 					ReviewerID: "Rev3",
 					Records: []record{
 						{Header: "ID", Text: "Rev3.3"},
@@ -55,7 +65,7 @@ func TestAsDocresponses(t *testing.T) {
 			},
 		},
 		{
-			name:    "Contains empty records",
+			name:    "Skips empty records",
 			headers: []string{"ID", "Comment", "response"},
 			records: [][]string{
 				{"Rev2.6", "Another comment", "Another response"},
@@ -63,6 +73,7 @@ func TestAsDocresponses(t *testing.T) {
 			},
 			expected: []response{
 				{
+					ID:         "Rev2.6",
 					ReviewerID: "Rev2",
 					Records: []record{
 						{Header: "ID", Text: "Rev2.6"},
@@ -70,10 +81,6 @@ func TestAsDocresponses(t *testing.T) {
 						{Header: "response", Text: "Another response"},
 					},
 				},
-				{
-					ReviewerID: "",
-					Records:    []record{},
-				},
 			},
 		},
 		{
@@ -81,10 +88,10 @@ func TestAsDocresponses(t *testing.T) {
 			headers: []string{"ID", "Comment", "Response"},
 			records: [][]string{
 				{"Rev2.8", "Another comment"},
-				{},
 			},
 			expected: []response{
 				{
+					ID:         "Rev2.8",
 					ReviewerID: "Rev2",
 					Records: []record{
 						{Header: "ID", Text: "Rev2.8"},
@@ -92,9 +99,23 @@ func TestAsDocresponses(t *testing.T) {
 						{Header: "Response", Text: ""},
 					},
 				},
+			},
+		},
+		{
+			name:    "Escapes headers and cells",
+			headers: []string{"ID", "Comment #", "Response"},
+			records: [][]string{
+				{"Rev1.1", "50% more", "See Rev1.1"},
+			},
+			expected: []response{
 				{
-					ReviewerID: "",
-					Records:    []record{},
+					ID:         "Rev1.1",
+					ReviewerID: "Rev1",
+					Records: []record{
+						{Header: "ID", Text: "Rev1.1"},
+						{Header: `Comment \#`, Text: `50\% more`},
+						{Header: "Response", Text: `See \hyperref[Rev1.1]{Rev1.1}`},
+					},
 				},
 			},
 		},
@@ -102,20 +123,25 @@ func TestAsDocresponses(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := asDocResponses(tt.headers, tt.records)
-			if len(result) != len(tt.expected) {
-				t.Errorf("asDocresponses() length = %d; want %d", len(result), len(tt.expected))
+			td := &reader.TabularData{Headers: tt.headers, Records: tt.records}
+			rj := model.New(td, common.Options{})
+			refs := common.NewCrossRefs(td.Records, common.Labels("", td.Records), nil)
+			comments := rj.Comments()
+			if len(comments) != len(tt.expected) {
+				t.Fatalf("comments length = %d; want %d", len(comments), len(tt.expected))
 			}
-			for i, res := range result {
-				if res.ReviewerID != tt.expected[i].ReviewerID {
-					t.Errorf("asDocresponses()[%d].ReviewerID = %q; want %q", i, res.ReviewerID, tt.expected[i].ReviewerID)
+			for i, c := range comments {
+				res := asDocResponse(rj, c, newTextEscaper(common.Options{}), refs, false)
+				if res.ID != tt.expected[i].ID || res.ReviewerID != tt.expected[i].ReviewerID {
+					t.Errorf("asDocResponse()[%d] = %q of %q; want %q of %q", i, res.ID, res.ReviewerID, tt.expected[i].ID, tt.expected[i].ReviewerID)
 				}
 				if len(res.Records) != len(tt.expected[i].Records) {
-					t.Errorf("asDocresponses()[%d].Records length = %d; want %d", i, len(res.Records), len(tt.expected[i].Records))
+					t.Errorf("asDocResponse()[%d].Records length = %d; want %d", i, len(res.Records), len(tt.expected[i].Records))
+					continue
 				}
 				for j, rec := range res.Records {
-					if rec.Header != tt.expected[i].Records[j].Header || rec.Text != tt.expected[i].Records[j].Text {
-						t.Errorf("asDocresponses()[%d].Records[%d] = %+v; want %+v", i, j, rec, tt.expected[i].Records[j])
+					if rec != tt.expected[i].Records[j] {
+						t.Errorf("asDocResponse()[%d].Records[%d] = %+v; want %+v", i, j, rec, tt.expected[i].Records[j])
 					}
 				}
 			}
@@ -126,19 +152,19 @@ func TestAsDocresponses(t *testing.T) {
 func TestAsDocHeaders(t *testing.T) {
 	tests := []struct {
 		name     string
-		headers  []string
+		names    []string
 		expected []header
 	}{
 		{
-			name:    "Single header",
-			headers: []string{"ID"},
+			name:  "Single header",
+			names: []string{"ID"},
 			expected: []header{
 				{Name: "ID", Idx: 2},
 			},
 		},
 		{
-			name:    "Multiple headers",
-			headers: []string{"ID", "Comment", "Response"},
+			name:  "Multiple headers",
+			names: []string{"ID", "Comment", "Response"},
 			expected: []header{
 				{Name: "ID", Idx: 2},
 				{Name: "Comment", Idx: 3},
@@ -147,28 +173,19 @@ func TestAsDocHeaders(t *testing.T) {
 		},
 		{
 			name:     "No headers",
-			headers:  []string{},
+			names:    []string{},
 			expected: []header{},
 		},
-		{
-			name:    "Headers with special characters",
-			headers: []string{"ID#", "Comment%", "Response&"},
-			expected: []header{
-				{Name: "ID#", Idx: 2},
-				{Name: "Comment%", Idx: 3},
-				{Name: "Response&", Idx: 4},
-			},
-		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := asDocHeaders(tt.headers)
+			result := asDocHeaders(tt.names)
 			if len(result) != len(tt.expected) {
 				t.Errorf("asDocHeaders() length = %d; want %d", len(result), len(tt.expected))
 			}
 			for i, res := range result {
-				if res.Name != tt.expected[i].Name || res.Idx != tt.expected[i].Idx {
+				if res != tt.expected[i] {
 					t.Errorf("asDocHeaders()[%d] = %+v; want %+v", i, res, tt.expected[i])
 				}
 			}
@@ -176,6 +193,24 @@ func TestAsDocHeaders(t *testing.T) {
 	}
 }
 
+func TestCreateRound_Headers(t *testing.T) {
+	td := &reader.TabularData{
+		Headers: []string{"ID", "Response", "Status", "Comment#"},
+		Records: [][]string{{"Rev1.1", "r", "done", "c"}},
+		Roles:   []reader.Role{reader.IDRole, reader.ResponseRole, reader.HiddenRole, reader.CommentRole},
+	}
+	r := createRound(td, common.Options{}, "")
+	if r.Comment == nil || *r.Comment != (header{Name: `Comment\#`, Idx: 3}) {
+		t.Errorf("createRound().Comment = %+v; want Comment\\# at 3", r.Comment)
+	}
+	if want := []header{{Name: "Response", Idx: 4}}; len(r.Details) != 1 || r.Details[0] != want[0] {
+		t.Errorf("createRound().Details = %+v; want %+v", r.Details, want)
+	}
+	if r.LenHeaders != 4 {
+		t.Errorf("createRound().LenHeaders = %d; want 4", r.LenHeaders)
+	}
+}
+
 func TestLatexFileExtension(t *testing.T) {
 	lt := NewLatexTemplate()
 	got := lt.FileExtension()
@@ -197,6 +232,14 @@ func TestEscape(t *testing.T) {
 		{"A_B_C", "A\\_B\\_C"},
 		{"Ampersand & more", "Ampersand \\& more"},
 		{"Tilde~Caret^", "Tilde\\textasciitilde{}Caret\\textasciicircum{}"},
+		{"\\cite{key}", "\\textbackslash{}cite\\{key\\}"},
+		{"a < b > c | d", "a \\textless{} b \\textgreater{} c \\textbar{} d"},
+		{"“Smart” ‘quotes’ – and — dashes…", "``Smart'' `quotes' -- and --- dashes\\ldots{}"},
+		{"100\u00a0€", "100~\\euro{}"},
+		{"Umlauts äöü and Łódź", "Umlauts äöü and Łódź"},
+		{"x ≤ 5 → done ✓", "x $\\leq$ 5 $\\rightarrow$ done (yes)"},
+		{"Emoji 🎉 and CJK 漢字", "Emoji ? and CJK ??"},
+		{"control\x00\x1b chars\r\n", "control chars\n"},
 		{"", ""},
 	}
 
@@ -210,22 +253,546 @@ func TestEscape(t *testing.T) {
 	}
 }
 
+// LaTeX commands written in cells stay as they are with math-aware escaping.
 func TestEscapeShouldStaySame(t *testing.T) {
+	esc := newTextEscaper(common.Options{Escaping: common.MathEscaping})
 	tests := []struct {
 		input string
 	}{
 		{"A previous study by \\citet{bauer2025} showed that XZY."},
 		{"A previous study by Bauer et al. showed that XZY \\cite{bauer2025}."},
-		{"Software testing is a systematic process for the verification and validation of software against its specifications~\\cite{myers2012ArtSoftwareTesting, sommerville2016SoftwareEngineering,washizaki2024SWEBOKGuideSoftware}. Verification\\footnote{Verification: \\emph{``Are we building the product right?''}\\cite{boehm1984verifying}} ensures that the software meets its defined requirements, while validation\\footnote{Validation: \\emph{``Are we building the right product?''}~\\cite{boehm1984verifying}} ensures the software aligns with the customer’s expectations. It involves executing software with defined inputs and assessing the resulting outputs against expected outcomes."},
+		{"Software testing is a systematic process for the verification and validation of software against its specifications~\\cite{myers2012ArtSoftwareTesting, sommerville2016SoftwareEngineering,washizaki2024SWEBOKGuideSoftware}. Verification\\footnote{Verification: \\emph{``Are we building the product right?''}\\cite{boehm1984verifying}} ensures that the software meets its defined requirements, while validation\\footnote{Validation: \\emph{``Are we building the right product?''}~\\cite{boehm1984verifying}} ensures the software aligns with the customer's expectations. It involves executing software with defined inputs and assessing the resulting outputs against expected outcomes."},
 		{"Our artifacts are made publicly available on Zenodo\\footnote{\\href{https://zenodo.org}{https://zenodo.org}}"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.input, func(t *testing.T) {
-			got := escape(tt.input)
+			got := esc.escape(tt.input)
 			if got != tt.input {
 				t.Errorf("escape(%q) = %q; want %q", tt.input, got, tt.input)
 			}
 		})
 	}
 }
+
+func TestRenderMetadata(t *testing.T) {
+	td := reader.TabularData{
+		Headers: []string{"ID", "Comment", "Response"},
+		Records: [][]string{{"Rev1.1", "Comment", "Response"}},
+	}
+
+	tests := []struct {
+		name     string
+		meta     common.Metadata
+		expected []string
+	}{
+		{
+			name: "placeholders without metadata",
+			meta: common.Metadata{},
+			expected: []string{
+				"\\newcommand{\\paperId}{YOUR PAPER ID}",
+				"\\newcommand{\\paperTitle}{YOUR TITLE}",
+				"Dear Editor,",
+				"\\item ABC",
+				"\nAUTHORS\n",
+			},
+		},
+		{
+			name: "escaped metadata",
+			meta: common.Metadata{
+				Title:        "Tests & Reviews",
+				ManuscriptID: "EMSE_42",
+				Authors:      []string{"A. Bauer", "M. Doe"},
+				Editor:       "Prof. Smith",
+				Venue:        "EMSE",
+				CoverLetter:  "Thanks for 100% of the feedback.",
+				KeyChanges:   []string{"Switched Tables 3 & 4"},
+			},
+			expected: []string{
+				"\\newcommand{\\paperId}{EMSE\\_42}",
+				"\\newcommand{\\paperTitle}{Tests \\& Reviews}",
+				" EMSE \\vspace{0.5cm}",
+				"Dear Prof. Smith,",
+				"Thanks for 100\\% of the feedback.",
+				"\\item Switched Tables 3 \\& 4",
+				"\nA. Bauer, M. Doe\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := renderString(common.NewDocument(&td), common.Options{Meta: tt.meta})
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			for _, want := range tt.expected {
+				if !strings.Contains(out, want) {
+					t.Errorf("Render() output does not contain %q", want)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderRounds(t *testing.T) {
+	wb := reader.Workbook{Sheets: []reader.Sheet{
+		{Name: "Round 1", Data: &reader.TabularData{
+			Headers: []string{"ID", "Comment", "Response"},
+			Records: [][]string{{"Rev1.1", "First comment", "First response"}},
+		}},
+		{Name: "Round_2", Data: &reader.TabularData{
+			Headers: []string{"ID", "Comment", "Response", "Action"},
+			Records: [][]string{{"Rev2.1", "Second comment", "Second response", "Done"}},
+		}},
+	}}
+
+	tests := []struct {
+		name     string
+		appendix bool
+		expected []string
+	}{
+		{
+			name: "rounds in order",
+			expected: []string{
+				"\\definecolor{colorRound1Rev1}",
+				"\\definecolor{colorRound2Rev2}",
+				"\\newcommand{\\response}[4]{",
+				"\\section{Round 1}",
+				"\\subsection{Reviewer 1}",
+				"\\label{Round1:Rev1.1}\\pdfbookmark[3]{Rev1.1}{Round1:Rev1.1}",
+				"colorRound1Rev1",
+				"\\section{Round\\_2}\n\n\\renewcommand{\\response}[5]{",
+				"\\subsection{Reviewer 2}",
+				"colorRound2Rev2",
+			},
+		},
+		{
+			name:     "earlier rounds in appendix",
+			appendix: true,
+			expected: []string{
+				"\\newcommand{\\response}[5]{",
+				"\\section{Round\\_2}",
+				"\\appendix\n\n\\section{Round 1}\n\n\\renewcommand{\\response}[4]{",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := renderString(common.NewRoundsDocument(&wb), common.Options{AppendixRounds: tt.appendix})
+			if err != nil {
+				t.Fatalf("RenderRounds() error = %v", err)
+			}
+			for _, want := range tt.expected {
+				if !strings.Contains(out, want) {
+					t.Errorf("RenderRounds() output does not contain %q", want)
+				}
+			}
+			if tt.appendix && strings.Index(out, "Round\\_2") > strings.Index(out, "Round 1") {
+				t.Errorf("RenderRounds() last round is not rendered before the appendix")
+			}
+		})
+	}
+
+	if _, err := renderString(common.NewRoundsDocument(&reader.Workbook{}), common.Options{}); err == nil {
+		t.Errorf("RenderRounds() expected error for empty workbook")
+	}
+}
+
+func TestRichText(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    reader.RichText
+		expected string
+	}{
+		{
+			name:     "plain runs",
+			input:    reader.RichText{{Text: "No "}, {Text: "formatting & more"}},
+			expected: "No formatting \\& more",
+		},
+		{
+			name:     "bold, italic, and underline",
+			input:    reader.RichText{{Text: "We "}, {Text: "fixed ", Bold: true}, {Text: "50%", Italic: true, Underline: true}},
+			expected: "We \\textbf{fixed} \\underline{\\emph{50\\%}}",
+		},
+		{
+			name:     "link",
+			input:    reader.RichText{{Text: "see docs", Link: "https://example.com/a%20b#sec"}},
+			expected: "\\href{https://example.com/a\\%20b\\#sec}{see docs}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := richText(tt.input, textEscaper{})
+			if got != tt.expected {
+				t.Errorf("richText() = %q; want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRenderRichText(t *testing.T) {
+	td := reader.TabularData{
+		Headers: []string{"ID", "Comment", "Response"},
+		Records: [][]string{{"Rev1.1", "A comment", "We fixed it"}},
+		Rich:    [][]reader.RichText{{nil, nil, {{Text: "We "}, {Text: "fixed", Bold: true}, {Text: " it"}}}},
+	}
+
+	out, err := renderString(common.NewDocument(&td), common.Options{})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !strings.Contains(out, "We \\textbf{fixed} it") {
+		t.Errorf("Render() output does not contain the formatted response")
+	}
+	if td.Records[0][2] != "We fixed it" {
+		t.Errorf("Render() modified the tabular data")
+	}
+}
+
+func TestRenderCellMarkup(t *testing.T) {
+	td := reader.TabularData{
+		Headers: []string{"ID", "Comment", "Response"},
+		Records: [][]string{{"Rev1.1", "Use **bold** & `code_name`", "Changes:\n- [Docs](https://example.com/#a)\n- Table 3"}},
+	}
+
+	tests := []struct {
+		name     string
+		markup   bool
+		expected []string
+	}{
+		{
+			name:     "escaped without cell markup",
+			expected: []string{"Use **bold** \\& `code\\_name`", "Changes:\n- [Docs](https://example.com/\\#a)\n- Table 3"},
+		},
+		{
+			name:   "converted with cell markup",
+			markup: true,
+			expected: []string{
+				"Use \\textbf{bold} \\& \\texttt{code\\_name}",
+				"Changes:\n\n\\begin{itemize}\n  \\item \\href{https://example.com/\\#a}{Docs}\n  \\item Table 3\n\\end{itemize}",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := renderString(common.NewDocument(&td), common.Options{CellMarkup: tt.markup})
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			for _, want := range tt.expected {
+				if !strings.Contains(out, want) {
+					t.Errorf("Render() output does not contain %q", want)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderEscaping(t *testing.T) {
+	td := reader.TabularData{
+		Headers: []string{"ID", "Comment", "Response"},
+		Records: [][]string{{"Rev1.1", "Is $p < 0.05$ for 5% of $n$?", "As shown by {{raw}}\\citet{doe_2024}{{/raw}}, it costs $5"}},
+	}
+
+	tests := []struct {
+		name     string
+		escaping common.Escaping
+		expected []string
+	}{
+		{
+			name:     "strict",
+			escaping: common.StrictEscaping,
+			expected: []string{
+				"Is \\$p \\textless{} 0.05\\$ for 5\\% of \\$n\\$?",
+				"As shown by \\{\\{raw\\}\\}\\textbackslash{}citet\\{doe\\_2024\\}\\{\\{/raw\\}\\}, it costs \\$5",
+			},
+		},
+		{
+			name:     "math-aware",
+			escaping: common.MathEscaping,
+			expected: []string{"Is $p < 0.05$ for 5\\% of $n$?", "As shown by \\citet{doe_2024}, it costs \\$5"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := renderString(common.NewDocument(&td), common.Options{Escaping: tt.escaping})
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			for _, want := range tt.expected {
+				if !strings.Contains(out, want) {
+					t.Errorf("Render() output does not contain %q", want)
+				}
+			}
+		})
+	}
+}
+
+func TestWarnings(t *testing.T) {
+	td := &reader.TabularData{
+		Headers: []string{"ID", "Comment 💬", "Response", "Hidden"},
+		Records: [][]string{
+			{"Rev1.1", "Great 🎉", "Thanks 🎉 漢字"},
+			{"Rev1.2", "x ≤ 5", "ok", "🚀"},
+			{"Rev1.3", "$🎉$", "fine"},
+		},
+		Rows:  []int{2, 3, 4},
+		Roles: []reader.Role{reader.NoRole, reader.NoRole, reader.NoRole, reader.HiddenRole},
+	}
+
+	got := (&Latex{}).Warnings(td, common.Options{Escaping: common.MathEscaping})
+	want := []string{
+		`column "Comment 💬" contains characters "💬" that LaTeX cannot typeset, they are replaced by "?"`,
+		`row 2 contains characters "🎉漢字" that LaTeX cannot typeset, they are replaced by "?"`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Warnings() = %q; want %q", got, want)
+	}
+
+	if got := (&Latex{}).Warnings(&reader.TabularData{}, common.Options{}); got != nil {
+		t.Errorf("Warnings() of empty data = %q; want none", got)
+	}
+}
+
+func TestRenderIDScheme(t *testing.T) {
+	td := reader.TabularData{
+		Headers: []string{"ID", "Comment", "Response"},
+		Records: [][]string{
+			{"AE-R2-C14", "First comment", "First response"},
+			{"Editor", "Second comment", "Second response"},
+		},
+	}
+	scheme, err := common.ParseIDScheme("reviewer-comment")
+	if err != nil {
+		t.Fatal(err)
+	}
+	verbose, err := common.ParseIDScheme("verbose")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := renderString(common.NewDocument(&td), common.Options{IDScheme: scheme})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	for _, want := range []string{"\\definecolor{colorAER2}{HTML}{F2CF80}", "\\response{\n  colorAER2\n}", "\\response{\n  colorRevDefault\n}"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Render() output does not contain %q", want)
+		}
+	}
+
+	td.Records = [][]string{{"Reviewer #3 / Comment 1", "Comment", "Response"}}
+	out, err = renderString(common.NewDocument(&td), common.Options{IDScheme: verbose})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !strings.Contains(out, "\\definecolor{colorReviewer3}{HTML}{F2CF80}") {
+		t.Errorf("Render() output does not contain %q", "\\definecolor{colorReviewer3}{HTML}{F2CF80}")
+	}
+}
+
+func TestRenderDistinctColors(t *testing.T) {
+	td := reader.TabularData{
+		Headers: []string{"ID", "Comment", "Response"},
+		Records: [][]string{{"R-1.1", "c1", "r1"}, {"R1.1", "c2", "r2"}},
+	}
+	scheme, err := common.ParseIDScheme(`^(?P<reviewer>.+)\.(?P<comment>\d+)$`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := renderString(common.NewDocument(&td), common.Options{IDScheme: scheme})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	for _, want := range []string{"\\definecolor{colorR1}{HTML}", "\\definecolor{colorR12}{HTML}", "\\response{\n  colorR1\n}", "\\response{\n  colorR12\n}"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Render() output does not contain %q", want)
+		}
+	}
+}
+
+func TestRenderSections(t *testing.T) {
+	td := reader.TabularData{
+		Headers: []string{"ID", "Comment", "Response"},
+		Records: [][]string{
+			{"ED.1", "Editor comment", "Editor response"},
+			{"Rev1.1", "First comment", "First response"},
+			{"ED.2", "Second editor comment", "Second editor response"},
+		},
+	}
+
+	out, err := renderString(common.NewDocument(&td), common.Options{})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	expected := []string{
+		"\\section{Editor}",
+		"\\phantomsection\\label{ED.1}\\pdfbookmark[2]{ED.1}{ED.1}",
+		"\\phantomsection\\label{ED.2}\\pdfbookmark[2]{ED.2}{ED.2}",
+		"\\section{Reviewer 1}",
+		"\\phantomsection\\label{Rev1.1}\\pdfbookmark[2]{Rev1.1}{Rev1.1}",
+	}
+	last := -1
+	for _, want := range expected {
+		idx := strings.Index(out, want)
+		if idx < 0 {
+			t.Fatalf("Render() output does not contain %q", want)
+		}
+		if idx < last {
+			t.Errorf("Render() output contains %q out of order", want)
+		}
+		last = idx
+	}
+	if strings.Contains(out, "\\tableofcontents") {
+		t.Errorf("Render() output contains a table of contents without TableOfContents")
+	}
+
+	out, err = renderString(common.NewDocument(&td), common.Options{TableOfContents: true})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !strings.Contains(out, "\\tableofcontents") {
+		t.Errorf("Render() output does not contain a table of contents")
+	}
+}
+
+func TestRenderCrossRefs(t *testing.T) {
+	td := reader.TabularData{
+		Headers: []string{"ID", "Comment", "Response"},
+		Records: [][]string{
+			{"Rev1.1", "First comment", "See our answer to Rev1.2."},
+			{"Rev1.2", "Second comment", "As for **Rev1.1**, we fixed $Rev1.1$"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		opts     common.Options
+		expected []string
+	}{
+		{
+			name:     "strict",
+			expected: []string{"See our answer to \\hyperref[Rev1.2]{Rev1.2}.", "As for **\\hyperref[Rev1.1]{Rev1.1}**"},
+		},
+		{
+			name:     "markup and math",
+			opts:     common.Options{CellMarkup: true, Escaping: common.MathEscaping},
+			expected: []string{"As for \\textbf{\\hyperref[Rev1.1]{Rev1.1}}, we fixed $Rev1.1$"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := renderString(common.NewDocument(&td), tt.opts)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			for _, want := range tt.expected {
+				if !strings.Contains(out, want) {
+					t.Errorf("Render() output does not contain %q", want)
+				}
+			}
+			if strings.Contains(out, "{ %ID\n\\hyperref") {
+				t.Errorf("Render() output links the ID column")
+			}
+		})
+	}
+}
+
+func TestRenderCrossRefs_ReviewerColumn(t *testing.T) {
+	td := reader.TabularData{
+		Headers: []string{"No", "Reviewer", "Comment", "Response"},
+		Records: [][]string{
+			{"1", "R1", "c1", "Fix Section 2."},
+			{"2", "R1", "c2", "Table 1 updated"},
+			{"1", "R2", "c3", "Done in Section 2 and 1 more."},
+		},
+		Roles: []reader.Role{reader.IDRole, reader.ReviewerRole, reader.CommentRole, reader.ResponseRole},
+	}
+
+	out, err := renderString(common.NewDocument(&td), common.Options{})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	for _, want := range []string{"Fix Section 2.", "Table 1 updated", "Done in Section 2 and 1 more."} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Render() output does not contain %q", want)
+		}
+	}
+}
+
+func TestRenderRoles(t *testing.T) {
+	td := reader.TabularData{
+		Headers: []string{"Status", "Response", "No", "Reviewer", "Remark"},
+		Records: [][]string{
+			{"done", "We fixed it.", "1", "R2", "Typo in Sec. 2"},
+		},
+		Roles: []reader.Role{reader.HiddenRole, reader.NoRole, reader.IDRole, reader.ReviewerRole, reader.CommentRole},
+	}
+	td.Keep(td.Headers)
+
+	out, err := renderString(common.NewDocument(&td), common.Options{})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	expected := []string{
+		"\\definecolor{colorR2}",
+		"\\newcommand{\\response}[4]{",
+		"\\textbf{Comment:} #3\n    \\tcblower\n    \\textbf{Response:} #4\n",
+		"\\section{Reviewer 2}",
+		"{ %No\n1\n}\n{ %Remark\nTypo in Sec. 2\n}\n{ %Response\nWe fixed it.\n}",
+	}
+	last := -1
+	for _, want := range expected {
+		idx := strings.Index(out, want)
+		if idx < 0 {
+			t.Fatalf("Render() output does not contain %q", want)
+		}
+		if idx < last {
+			t.Errorf("Render() output contains %q out of order", want)
+		}
+		last = idx
+	}
+	for _, unwanted := range []string{"done", "%Status", "%Reviewer"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("Render() output contains the hidden or reviewer column %q", unwanted)
+		}
+	}
+}
+
+func TestRenderCustomTemplate(t *testing.T) {
+	td := reader.TabularData{
+		Headers: []string{"ID", "Comment", "Response"},
+		Records: [][]string{{"Rev1.1", "A comment", "A response"}},
+	}
+	custom := &common.CustomTemplate{
+		Name: "house.tmpl",
+		Text: `{{ range .Rounds }}{{ range .Sections }}{{ .Name }}:{{ range .Responses }} {{ .ID }}{{ end }}{{ end }}{{ end }}`,
+	}
+
+	out, err := renderString(common.NewDocument(&td), common.Options{Template: custom})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if want := "Reviewer 1: Rev1.1"; out != want {
+		t.Errorf("Render() = %q; want %q", out, want)
+	}
+
+	custom.Text = "{{ .Unknown }}"
+	if _, err := renderString(common.NewDocument(&td), common.Options{Template: custom}); err == nil {
+		t.Error("Render() with an unknown field returned no error")
+	}
+}
+
+// renderString renders the document with the latex template into a string.
+func renderString(doc common.Document, opts common.Options) (string, error) {
+	var b strings.Builder
+	err := NewLatexTemplate().Render(context.Background(), &b, doc, opts)
+	return b.String(), err
+}
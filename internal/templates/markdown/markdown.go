@@ -0,0 +1,171 @@
+package markdown
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/andreas-bauer/rejoinderoo/internal/model"
+	"github.com/andreas-bauer/rejoinderoo/internal/templates/common"
+)
+
+// Markdown handles escaping special characters for Markdown templates.
+type Markdown struct{}
+
+type record struct {
+	Header string
+	Text   string
+}
+
+type response struct {
+	ID      string
+	Records []record
+}
+
+// reviewer holds the responses to a reviewer; Name is the heading of the section, e.g., "Reviewer 1".
+type reviewer struct {
+	ReviewerID string
+	Name       string
+	Responses  []response
+}
+
+type document struct {
+	Meta      common.Metadata
+	Reviewers []reviewer
+}
+
+//go:embed markdown.tmpl
+var file string
+
+func NewMarkdownTemplate() *Markdown {
+	return &Markdown{}
+}
+
+// FileExtension returns the file extension for Markdown templates.
+func (m *Markdown) FileExtension() string {
+	return ".md"
+}
+
+// Builtin returns the source of the built-in Markdown template, e.g., as a starting point for a custom template.
+func (m *Markdown) Builtin() string {
+	return file
+}
+
+// Render writes the Markdown document with the responses of the document to w.
+func (m *Markdown) Render(ctx context.Context, w io.Writer, d common.Document, opts common.Options) error {
+	td, err := d.Single("Markdown")
+	if err != nil {
+		return err
+	}
+	doc := createDoc(model.New(td, opts))
+	doc.Meta = opts.Meta.Escaped(escape)
+
+	name, text := opts.Template.Source("markdown", file)
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return fmt.Errorf("parsing markdown template: %w", err)
+	}
+
+	err = tmpl.Execute(common.NewContextWriter(ctx, w), doc)
+	if err != nil {
+		return fmt.Errorf("executing markdown template: %w", err)
+	}
+
+	return nil
+}
+
+// createDoc converts the rejoinder to the document of the template with the reviewers in their order.
+// The metadata is escaped separately.
+func createDoc(rj model.Rejoinder) document {
+	doc := document{
+		Reviewers: make([]reviewer, len(rj.Reviewers)),
+	}
+	for i, rev := range rj.Reviewers {
+		doc.Reviewers[i] = reviewer{ReviewerID: escape(rev.ID), Name: escape(rev.Name)}
+		for _, c := range rev.Comments {
+			doc.Reviewers[i].Responses = append(doc.Reviewers[i].Responses, asDocResponse(rj, c))
+		}
+	}
+	return doc
+}
+
+// asDocResponse converts a comment to a response with the comment and the details as records.
+func asDocResponse(rj model.Rejoinder, c model.Comment) response {
+	fields := rj.Fields(c)
+	res := response{
+		ID:      escape(c.ID.Text),
+		Records: make([]record, 0, len(fields)),
+	}
+	for _, cell := range fields {
+		res.Records = append(res.Records, record{
+			Header: escape(cell.Header),
+			Text:   escape(cell.Text),
+		})
+	}
+	return res
+}
+
+// escape escapes special characters for Markdown.
+// Line breaks within a cell are kept as hard line breaks and blank lines as paragraph breaks.
+func escape(input string) string {
+	replacer := strings.NewReplacer(
+		"\\", "\\\\",
+		"`", "\\`",
+		"*", "\\*",
+		"_", "\\_",
+		"[", "\\[",
+		"]", "\\]",
+		"<", "\\<",
+		">", "\\>",
+		"#", "\\#",
+		"|", "\\|",
+		"&", "\\&",
+	)
+
+	var paragraphs []string
+	var lines []string
+	for line := range strings.SplitSeq(strings.ReplaceAll(input, "\r\n", "\n"), "\n") {
+		line = strings.TrimRight(line, " \t")
+		if line == "" {
+			if len(lines) > 0 {
+				paragraphs = append(paragraphs, strings.Join(lines, "\\\n"))
+				lines = nil
+			}
+			continue
+		}
+		lines = append(lines, escapeLineStart(replacer.Replace(line)))
+	}
+	if len(lines) > 0 {
+		paragraphs = append(paragraphs, strings.Join(lines, "\\\n"))
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// escapeLineStart escapes characters that start a block element
+// (lists, thematic breaks, setext headings) when they appear at the beginning of a line.
+// An indentation of four or more columns, which starts a code block, is kept as non-breaking spaces.
+func escapeLineStart(line string) string {
+	trimmed := strings.TrimLeft(line, " \t")
+	indent := line[:len(line)-len(trimmed)]
+	if trimmed == "" {
+		return line
+	}
+	if width := len(indent) + 3*strings.Count(indent, "\t"); width >= 4 {
+		indent = strings.Repeat("&nbsp;", width)
+	}
+
+	switch trimmed[0] {
+	case '-', '+', '=':
+		return indent + "\\" + trimmed
+	}
+
+	// ordered list markers, e.g., "1." or "2)"
+	digits := len(trimmed) - len(strings.TrimLeft(trimmed, "0123456789"))
+	if digits > 0 && digits < len(trimmed) && (trimmed[digits] == '.' || trimmed[digits] == ')') {
+		return indent + trimmed[:digits] + "\\" + trimmed[digits:]
+	}
+	return indent + trimmed
+}
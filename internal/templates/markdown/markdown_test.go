@@ -0,0 +1,144 @@
+package markdown
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/andreas-bauer/rejoinderoo/internal/model"
+	"github.com/andreas-bauer/rejoinderoo/internal/reader"
+	"github.com/andreas-bauer/rejoinderoo/internal/templates/common"
+)
+
+func TestCreateDoc(t *testing.T) {
+	headers := []string{"ID", "Comment", "Response"}
+	records := [][]string{
+		{"Rev1.1", "First comment", "First response"},
+		{"Rev2.1", "Second comment", "Second response"},
+		{},
+		{"Rev1.2", "Third comment"},
+	}
+
+	doc := createDoc(model.New(&reader.TabularData{Headers: headers, Records: records}, common.Options{}))
+
+	if len(doc.Reviewers) != 2 {
+		t.Fatalf("createDoc() reviewers length = %d; want 2", len(doc.Reviewers))
+	}
+
+	rev1 := doc.Reviewers[0]
+	if rev1.ReviewerID != "Rev1" || len(rev1.Responses) != 2 {
+		t.Fatalf("createDoc() first reviewer = %+v; want Rev1 with 2 responses", rev1)
+	}
+	if rev1.Responses[1].ID != "Rev1.2" {
+		t.Errorf("createDoc() Rev1 second response ID = %q; want %q", rev1.Responses[1].ID, "Rev1.2")
+	}
+	want := []record{
+		{Header: "Comment", Text: "Third comment"},
+		{Header: "Response", Text: ""},
+	}
+	for i, rec := range rev1.Responses[1].Records {
+		if rec != want[i] {
+			t.Errorf("createDoc() Rev1.2 record[%d] = %+v; want %+v", i, rec, want[i])
+		}
+	}
+
+	rev2 := doc.Reviewers[1]
+	if rev2.ReviewerID != "Rev2" || len(rev2.Responses) != 1 {
+		t.Errorf("createDoc() second reviewer = %+v; want Rev2 with 1 response", rev2)
+	}
+}
+
+func TestRender_ReviewerNames(t *testing.T) {
+	td := &reader.TabularData{
+		Headers: []string{"ID", "Comment", "Response"},
+		Records: [][]string{{"Rev1.1", "c1", "r1"}, {".1", "c2", "r2"}},
+	}
+	out, err := renderString(common.NewDocument(td), common.Options{})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	for _, want := range []string{"\n## Reviewer 1\n", "\n## Other Comments\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Render() output does not contain %q", want)
+		}
+	}
+	if strings.Contains(out, "\n## \n") {
+		t.Errorf("Render() output contains an empty reviewer heading")
+	}
+}
+
+func TestMarkdownFileExtension(t *testing.T) {
+	md := NewMarkdownTemplate()
+	got := md.FileExtension()
+	want := ".md"
+	if got != want {
+		t.Errorf("FileExtension() = %q; want %q", got, want)
+	}
+}
+
+func TestRender(t *testing.T) {
+	td := reader.TabularData{
+		Headers: []string{"ID", "Comment", "Response"},
+		Records: [][]string{
+			{"Rev1.1", "Use *bold*", "Done"},
+		},
+	}
+
+	out, err := renderString(common.NewDocument(&td), common.Options{})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	for _, want := range []string{"## Reviewer 1\n", "### Rev1.1\n", "**Comment:**\n\nUse \\*bold\\*\n", "**Response:**\n\nDone\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Render() output does not contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"", ""},
+		{"plain text", "plain text"},
+		{"*bold* and _italic_", "\\*bold\\* and \\_italic\\_"},
+		{"`code`", "\\`code\\`"},
+		{"[link](url)", "\\[link\\](url)"},
+		{"<b>html</b>", "\\<b\\>html\\</b\\>"},
+		{"a | b", "a \\| b"},
+		{"# heading", "\\# heading"},
+		{"C:\\path", "C:\\\\path"},
+		{"- item", "\\- item"},
+		{"  + item", "  \\+ item"},
+		{"1. first", "1\\. first"},
+		{"2) second", "2\\) second"},
+		{"version 1.2", "version 1.2"},
+		{"line one\nline two", "line one\\\nline two"},
+		{"para one\n \npara two", "para one\n\npara two"},
+		{"- a\r\n- b", "\\- a\\\n\\- b"},
+		{"    code", "&nbsp;&nbsp;&nbsp;&nbsp;code"},
+		{"\tcode", "&nbsp;&nbsp;&nbsp;&nbsp;code"},
+		{"text\n      - item", "text\\\n&nbsp;&nbsp;&nbsp;&nbsp;&nbsp;&nbsp;\\- item"},
+		{"   three spaces", "   three spaces"},
+		{"Q&amp;A", "Q\\&amp;A"},
+		{"&copy; 2024", "\\&copy; 2024"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := escape(tt.input)
+			if got != tt.expected {
+				t.Errorf("escape(%q) = %q; want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+// renderString renders the document with the markdown template into a string.
+func renderString(doc common.Document, opts common.Options) (string, error) {
+	var b strings.Builder
+	err := NewMarkdownTemplate().Render(context.Background(), &b, doc, opts)
+	return b.String(), err
+}
@@ -0,0 +1,164 @@
+package templates
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/andreas-bauer/rejoinderoo/internal/templates/docx"
+	"github.com/andreas-bauer/rejoinderoo/internal/templates/html"
+	"github.com/andreas-bauer/rejoinderoo/internal/templates/latex"
+	"github.com/andreas-bauer/rejoinderoo/internal/templates/markdown"
+	"github.com/andreas-bauer/rejoinderoo/internal/templates/typst"
+)
+
+// Option is a setting that a format takes into account, e.g., the table of contents.
+// Its name is the name of the CLI flag, so that settings a format ignores can be reported.
+type Option struct {
+	Name        string
+	Description string
+}
+
+// Options of the formats.
+var (
+	OptionPalette  = Option{"palette", "color palette for the reviewers"}
+	OptionMarkup   = Option{"markup", "convert Markdown-like markup in cells"}
+	OptionEscaping = Option{"escaping", "pass inline math and raw blocks through"}
+	OptionTOC      = Option{"toc", "table of contents of the reviewer sections"}
+	OptionRounds   = Option{"rounds", "combine several review rounds into one document"}
+	OptionCustom   = Option{"template-file", "custom template that replaces the built-in one"}
+	OptionPDF      = Option{"pdf", "compile the generated rejoinder to PDF"}
+)
+
+// KnownOptions returns all options that formats can take into account.
+func KnownOptions() []Option {
+	return []Option{OptionPalette, OptionMarkup, OptionEscaping, OptionTOC, OptionRounds, OptionCustom, OptionPDF}
+}
+
+// Format describes an output format of the registry.
+type Format struct {
+	// Name is the display name of the format, e.g., "LaTeX".
+	Name string
+	// Aliases are further names that select the format, e.g., "tex".
+	Aliases []string
+	// Description summarizes the output, e.g., for help texts and forms.
+	Description string
+	// Extension is the file extension of the output, e.g., ".tex".
+	Extension string
+	// MIMEType is the media type of the output, e.g., for downloads.
+	MIMEType string
+	// Binary reports whether the output is binary data, e.g., a DOCX package, rather than text.
+	Binary bool
+	// Options lists the settings that the format takes into account.
+	Options []Option
+	// New creates a template of the format.
+	New func() Template
+}
+
+// Matches reports whether the name or one of the aliases of the format equals the given name.
+// The comparison is case-insensitive.
+func (f Format) Matches(name string) bool {
+	name = strings.TrimSpace(name)
+	return strings.EqualFold(f.Name, name) || slices.ContainsFunc(f.Aliases, func(a string) bool {
+		return strings.EqualFold(a, name)
+	})
+}
+
+// Supports reports whether the format takes the option into account.
+func (f Format) Supports(opt Option) bool {
+	return slices.Contains(f.Options, opt)
+}
+
+// registry holds the formats in the order they are offered. The first one is the default.
+var registry = []Format{
+	{
+		Name:        "LaTeX",
+		Aliases:     []string{"tex"},
+		Description: "LaTeX document with colored response boxes",
+		Extension:   ".tex",
+		MIMEType:    "application/x-latex",
+		Options:     []Option{OptionPalette, OptionMarkup, OptionEscaping, OptionTOC, OptionRounds, OptionCustom, OptionPDF},
+		New:         func() Template { return latex.NewLatexTemplate() },
+	},
+	{
+		Name:        "Typst",
+		Aliases:     []string{"typ"},
+		Description: "Typst document with colored response boxes",
+		Extension:   ".typ",
+		MIMEType:    "text/x-typst",
+		Options:     []Option{OptionPalette, OptionMarkup, OptionEscaping, OptionTOC, OptionRounds, OptionCustom, OptionPDF},
+		New:         func() Template { return typst.NewTypstTemplate() },
+	},
+	{
+		Name:        "Markdown",
+		Aliases:     []string{"md"},
+		Description: "plain Markdown with a section per reviewer",
+		Extension:   ".md",
+		MIMEType:    "text/markdown",
+		Options:     []Option{OptionCustom},
+		New:         func() Template { return markdown.NewMarkdownTemplate() },
+	},
+	{
+		Name:        "DOCX",
+		Aliases:     []string{"word"},
+		Description: "Word document with colored response tables",
+		Extension:   ".docx",
+		MIMEType:    docx.MIMEType,
+		Binary:      true,
+		Options:     []Option{OptionPalette},
+		New:         func() Template { return docx.NewDocxTemplate() },
+	},
+	{
+		Name:        "HTML",
+		Aliases:     []string{"htm"},
+		Description: "self-contained HTML page with colored response cards",
+		Extension:   ".html",
+		MIMEType:    "text/html",
+		Options:     []Option{OptionPalette, OptionCustom},
+		New:         func() Template { return html.NewHTMLTemplate() },
+	},
+}
+
+// Register adds a format to the registry, e.g., a format implemented outside of this package.
+// It is meant to be called during initialization and is not safe for concurrent use.
+func Register(f Format) error {
+	if f.Name == "" || f.New == nil {
+		return fmt.Errorf("format requires a name and a constructor")
+	}
+	for _, name := range append([]string{f.Name}, f.Aliases...) {
+		if existing, err := Lookup(name); err == nil {
+			return fmt.Errorf("format name %q is already used by %s", name, existing.Name)
+		}
+	}
+	registry = append(registry, f)
+	return nil
+}
+
+// Formats returns the registered formats in the order they are offered. The first one is the default.
+func Formats() []Format {
+	return slices.Clone(registry)
+}
+
+// Lookup returns the format with the given name or alias. An empty name selects the default format.
+func Lookup(name string) (Format, error) {
+	if strings.TrimSpace(name) == "" {
+		return registry[0], nil
+	}
+	for _, f := range registry {
+		if f.Matches(name) {
+			return f, nil
+		}
+	}
+	return Format{}, fmt.Errorf("template %q is not available, choose one of %v", name, Available())
+}
+
+// FormatsWith returns the names of the formats that take the option into account.
+func FormatsWith(opt Option) []string {
+	var res []string
+	for _, f := range registry {
+		if f.Supports(opt) {
+			res = append(res, f.Name)
+		}
+	}
+	return res
+}
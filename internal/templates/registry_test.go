@@ -0,0 +1,97 @@
+package templates
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		wantErr  bool
+	}{
+		{"LaTeX", "LaTeX", false},
+		{"tex", "LaTeX", false},
+		{" TYPST ", "Typst", false},
+		{"md", "Markdown", false},
+		{"Word", "DOCX", false},
+		{"", "LaTeX", false},
+		{"pdf", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Lookup(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Lookup(%q) error = %v; want error %v", tt.name, err, tt.wantErr)
+			}
+			if f.Name != tt.expected {
+				t.Errorf("Lookup(%q) = %q; want %q", tt.name, f.Name, tt.expected)
+			}
+		})
+	}
+}
+
+// TestFormats_MatchTemplates checks that the registry describes the templates it creates.
+func TestFormats_MatchTemplates(t *testing.T) {
+	for _, f := range Formats() {
+		t.Run(f.Name, func(t *testing.T) {
+			tmpl := f.New()
+			if ext := tmpl.FileExtension(); ext != f.Extension {
+				t.Errorf("Extension = %q; template has %q", f.Extension, ext)
+			}
+			if f.Supports(OptionCustom) != SupportsCustom(tmpl) {
+				t.Errorf("Supports(OptionCustom) = %v; SupportsCustom() = %v", f.Supports(OptionCustom), SupportsCustom(tmpl))
+			}
+			if f.Description == "" || f.MIMEType == "" {
+				t.Errorf("format %q lacks a description or MIME type", f.Name)
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	defer func(formats []Format) { registry = formats }(Formats())
+
+	plain := Format{
+		Name:      "Plain",
+		Aliases:   []string{"txt"},
+		Extension: ".md",
+		MIMEType:  "text/plain",
+		New:       func() Template { return mustNewTemplate(t, "Markdown") },
+	}
+	if err := Register(plain); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if !IsAvailable("txt") || !slices.Contains(Available(), "Plain") {
+		t.Errorf("Available() = %v; want registered format Plain", Available())
+	}
+
+	for _, f := range []Format{
+		{Name: "Word", New: plain.New},
+		{Name: "Other", Aliases: []string{"TXT"}, New: plain.New},
+		{Name: "Missing constructor"},
+	} {
+		if err := Register(f); err == nil {
+			t.Errorf("Register(%q) expected error", f.Name)
+		}
+	}
+}
+
+func TestFormatsWith(t *testing.T) {
+	got := FormatsWith(OptionTOC)
+	if !slices.Equal(got, []string{"LaTeX", "Typst"}) {
+		t.Errorf("FormatsWith(OptionTOC) = %v", got)
+	}
+}
+
+func TestKnownOptions_CoverFormats(t *testing.T) {
+	for _, f := range Formats() {
+		for _, opt := range f.Options {
+			if !slices.Contains(KnownOptions(), opt) {
+				t.Errorf("format %s has unknown option %q", f.Name, opt.Name)
+			}
+		}
+	}
+}
@@ -1,34 +1,162 @@
 package templates
 
 import (
-	"strings"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"slices"
 
 	"github.com/andreas-bauer/rejoinderoo/internal/reader"
-	"github.com/andreas-bauer/rejoinderoo/internal/templates/latex"
-	"github.com/andreas-bauer/rejoinderoo/internal/templates/typst"
+	"github.com/andreas-bauer/rejoinderoo/internal/templates/common"
 )
 
-// Template is an interface for templates.
+// Template renders the responses of a document in an output format, e.g., LaTeX or DOCX.
+// Text and binary formats share the same contract.
 type Template interface {
-	Render(td reader.TabularData) (string, error)
+	// Render writes the document to w. It returns the errors of parsing and executing the template,
+	// and stops with the error of the context once the context is done.
+	// Templates that cannot combine several review rounds return an error for such documents.
+	Render(ctx context.Context, w io.Writer, doc common.Document, opts common.Options) error
 	FileExtension() string
 }
 
-// Available returns a list of available template names.
+// Warner is implemented by templates that report problems of the tabular data that are specific
+// to their output format, e.g., characters that LaTeX cannot typeset, see Warnings.
+type Warner interface {
+	Warnings(td *reader.TabularData, opts common.Options) []string
+}
+
+// CustomizableTemplate is implemented by templates whose built-in source can be replaced
+// by a custom template, see common.Options.Template.
+type CustomizableTemplate interface {
+	Template
+	Builtin() string
+}
+
+// Available returns the names of the registered formats, see Formats.
 func Available() []string {
-	return []string{
-		"LaTeX",
-		"Typst",
+	names := make([]string, len(registry))
+	for i, f := range registry {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// IsAvailable reports whether a format with the given name or alias is registered.
+// The comparison is case-insensitive.
+func IsAvailable(name string) bool {
+	return slices.ContainsFunc(registry, func(f Format) bool {
+		return f.Matches(name)
+	})
+}
+
+// NewTemplate creates a template of the format with the given name or alias.
+// An empty name selects the default format; unknown names are an error that lists the available formats.
+func NewTemplate(name string) (Template, error) {
+	f, err := Lookup(name)
+	if err != nil {
+		return nil, err
 	}
+	return f.New(), nil
+}
+
+// SupportsCustom reports whether the built-in source of the template can be replaced by a custom template.
+func SupportsCustom(tmpl Template) bool {
+	_, ok := tmpl.(CustomizableTemplate)
+	return ok
+}
+
+// Customizable returns the names of the templates that can be replaced by a custom template.
+func Customizable() []string {
+	return FormatsWith(OptionCustom)
+}
+
+// sample is the tabular data that custom templates are validated with.
+var sample = reader.TabularData{
+	Headers: []string{"ID", "Comment", "Response", "Action"},
+	Records: [][]string{
+		{"ED.1", "Please address the comments of the reviewers.", "We addressed all comments.", ""},
+		{"Rev1.1", "The threats to validity are missing.", "We added them, see Rev1.2.", "Added Section 6."},
+		{"Rev1.2", "Typo in Section 2.", "Fixed.", "Fixed the typo."},
+	},
+}
+
+// ValidateCustom renders sample data with the custom template as source of the format's template
+// to report parse and execute errors, e.g., unknown fields, before any input is processed.
+// It stops with the error of the context once the context is done, e.g., for recursive templates.
+func ValidateCustom(ctx context.Context, f Format, custom *common.CustomTemplate) error {
+	tmpl := f.New()
+	if !SupportsCustom(tmpl) {
+		return fmt.Errorf("custom templates are only supported for %v", Customizable())
+	}
+	docs := []common.Document{common.NewDocument(&sample)}
+	if f.Supports(OptionRounds) {
+		docs = append(docs, common.NewRoundsDocument(&reader.Workbook{
+			Sheets: []reader.Sheet{{Name: "Round 1", Data: &sample}, {Name: "Round 2", Data: &sample}},
+		}))
+	}
+	for _, doc := range docs {
+		if err := tmpl.Render(ctx, io.Discard, doc, common.Options{Template: custom}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RenderBytes renders the document with the given template into memory,
+// e.g., to write the output file only if rendering succeeds.
+func RenderBytes(ctx context.Context, tmpl Template, doc common.Document, opts common.Options) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Render(ctx, &buf, doc, opts); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmatchedIDs reports the IDs in the first column that do not match the ID scheme of the options.
+// Templates render the responses of such IDs without a reviewer, unless the reviewer column names one.
+func UnmatchedIDs(td *reader.TabularData, opts common.Options) []string {
+	var res []string
+	for _, idx := range opts.IDScheme.WithReviewerColumn(td.Column(reader.ReviewerRole)).Unmatched(td.Records) {
+		res = append(res, fmt.Sprintf("ID %q in row %d does not match the ID scheme %s",
+			td.Records[idx][0], td.Row(idx), opts.IDScheme))
+	}
+	return res
+}
+
+// UnknownRefs reports references to IDs that do not exist in the first column,
+// e.g., "see Rev1.9" if reviewer Rev1 has no comment 9. See common.CrossRefs.
+// Hidden and reviewer columns are not rendered and therefore not checked.
+func UnknownRefs(td *reader.TabularData, opts common.Options) []string {
+	refs := common.NewCrossRefs(td.Records, nil, opts.IDScheme)
+	fields := td.Layout().Fields()
+	var res []string
+	for i, rec := range td.Records {
+		for _, j := range fields {
+			if j >= len(rec) {
+				continue
+			}
+			for _, id := range refs.Unknown(rec[j]) {
+				res = append(res, fmt.Sprintf("row %d refers to ID %q, which does not exist", td.Row(i), id))
+			}
+		}
+	}
+	return res
+}
+
+// Warnings reports the problems of the tabular data that do not prevent rendering,
+// see UnmatchedIDs and UnknownRefs.
+func Warnings(td *reader.TabularData, opts common.Options) []string {
+	return append(UnmatchedIDs(td, opts), UnknownRefs(td, opts)...)
 }
 
-// NewTemplate creates a new template based on the specified type.
-// NewTemplate defaults to returning a LaTeX template, if a given name is not recognized.
-func NewTemplate(name string) Template {
-	switch strings.ToLower(strings.TrimSpace(name)) {
-	case "typst":
-		return typst.NewTypstTemplate()
-	default:
-		return latex.NewLatexTemplate()
+// TemplateWarnings reports the problems of the tabular data, see Warnings, followed by those
+// that are specific to the output format of the template, see Warner.
+func TemplateWarnings(tmpl Template, td *reader.TabularData, opts common.Options) []string {
+	res := Warnings(td, opts)
+	if w, ok := tmpl.(Warner); ok {
+		res = append(res, w.Warnings(td, opts)...)
 	}
+	return res
 }
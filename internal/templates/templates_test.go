@@ -1,14 +1,23 @@
 package templates
 
 import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
 	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/andreas-bauer/rejoinderoo/internal/reader"
+	"github.com/andreas-bauer/rejoinderoo/internal/templates/common"
 )
 
 func TestNewTemplate_ReturnsLatexTemplateByDefault(t *testing.T) {
-	template := NewTemplate("")
-	if template == nil {
-		t.Fatal("Expected non-nil template")
+	template, err := NewTemplate("")
+	if err != nil {
+		t.Fatalf("NewTemplate() error = %v", err)
 	}
 	res := reflect.TypeOf(template).String()
 	if res != "*latex.Latex" {
@@ -17,9 +26,9 @@ func TestNewTemplate_ReturnsLatexTemplateByDefault(t *testing.T) {
 }
 
 func TestNewTemplate_ReturnsLatexTemplate(t *testing.T) {
-	template := NewTemplate("LaTeX")
-	if template == nil {
-		t.Fatal("Expected non-nil template")
+	template, err := NewTemplate("LaTeX")
+	if err != nil {
+		t.Fatalf("NewTemplate() error = %v", err)
 	}
 	res := reflect.TypeOf(template).String()
 	if res != "*latex.Latex" {
@@ -28,9 +37,9 @@ func TestNewTemplate_ReturnsLatexTemplate(t *testing.T) {
 }
 
 func TestNewTemplate_ReturnsTypstTemplate(t *testing.T) {
-	template := NewTemplate("Typst")
-	if template == nil {
-		t.Fatal("Expected non-nil template")
+	template, err := NewTemplate("Typst")
+	if err != nil {
+		t.Fatalf("NewTemplate() error = %v", err)
 	}
 	res := reflect.TypeOf(template).String()
 	if res != "*typst.Typst" {
@@ -38,19 +47,64 @@ func TestNewTemplate_ReturnsTypstTemplate(t *testing.T) {
 	}
 }
 
-func TestNewTemplate_ReturnsLatexTemplateForUnknownType(t *testing.T) {
-	template := NewTemplate("Unknown")
-	if template == nil {
-		t.Fatal("Expected non-nil template")
+func TestNewTemplate_ReturnsMarkdownTemplate(t *testing.T) {
+	template, err := NewTemplate("Markdown")
+	if err != nil {
+		t.Fatalf("NewTemplate() error = %v", err)
 	}
 	res := reflect.TypeOf(template).String()
-	if res != "*latex.Latex" {
-		t.Errorf("Expected template type '*latex.Latex', got '%s'", res)
+	if res != "*markdown.Markdown" {
+		t.Errorf("Expected template type '*markdown.Markdown', got '%s'", res)
+	}
+}
+
+func TestNewTemplate_ReturnsDocxTemplate(t *testing.T) {
+	for _, name := range []string{"DOCX", "Word"} {
+		template, err := NewTemplate(name)
+		if err != nil {
+			t.Fatalf("NewTemplate() error = %v", err)
+		}
+		res := reflect.TypeOf(template).String()
+		if res != "*docx.Docx" {
+			t.Errorf("Expected template type '*docx.Docx' for %q, got '%s'", name, res)
+		}
+	}
+}
+
+func TestNewTemplate_ReturnsHTMLTemplate(t *testing.T) {
+	template, err := NewTemplate("HTML")
+	if err != nil {
+		t.Fatalf("NewTemplate() error = %v", err)
+	}
+	res := reflect.TypeOf(template).String()
+	if res != "*html.HTML" {
+		t.Errorf("Expected template type '*html.HTML', got '%s'", res)
+	}
+}
+
+func TestNewTemplate_ReturnsErrorForUnknownType(t *testing.T) {
+	template, err := NewTemplate("Unknown")
+	if err == nil {
+		t.Fatalf("Expected error for unknown template, got %T", template)
+	}
+	want := `template "Unknown" is not available, choose one of [LaTeX Typst Markdown DOCX HTML]`
+	if err.Error() != want {
+		t.Errorf("Expected error %q, got %q", want, err.Error())
+	}
+}
+
+// mustNewTemplate creates the template with the given name and fails the test on error.
+func mustNewTemplate(t *testing.T, name string) Template {
+	t.Helper()
+	tmpl, err := NewTemplate(name)
+	if err != nil {
+		t.Fatalf("NewTemplate(%q) error = %v", name, err)
 	}
+	return tmpl
 }
 
 func TestAvailable_ReturnsCorrectTemplateNames(t *testing.T) {
-	expected := []string{"LaTeX", "Typst"}
+	expected := []string{"LaTeX", "Typst", "Markdown", "DOCX", "HTML"}
 	result := Available()
 
 	if len(result) != len(expected) {
@@ -63,3 +117,272 @@ func TestAvailable_ReturnsCorrectTemplateNames(t *testing.T) {
 		}
 	}
 }
+
+func TestFormat_Binary(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"LaTeX", false},
+		{"Typst", false},
+		{"Markdown", false},
+		{"DOCX", true},
+		{"HTML", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Lookup(tt.name)
+			if err != nil {
+				t.Fatalf("Lookup() error = %v", err)
+			}
+			if f.Binary != tt.expected {
+				t.Errorf("Lookup(%q).Binary = %v; want %v", tt.name, f.Binary, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsAvailable(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"LaTeX", true},
+		{"latex", true},
+		{" typst ", true},
+		{"HTML", true},
+		{"Unknown", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := IsAvailable(tt.name)
+			if got != tt.expected {
+				t.Errorf("IsAvailable(%q) = %v; want %v", tt.name, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRender_Rounds(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"LaTeX", true},
+		{"Typst", true},
+		{"Markdown", false},
+		{"DOCX", false},
+		{"HTML", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wb := &reader.Workbook{Sheets: []reader.Sheet{{Name: "Round 1", Data: &sample}}}
+			err := mustNewTemplate(t, tt.name).Render(context.Background(), io.Discard, common.NewRoundsDocument(wb), common.Options{})
+			if (err == nil) != tt.expected {
+				t.Errorf("Render() of several review rounds error = %v; want support %v", err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestUnmatchedIDs(t *testing.T) {
+	td := &reader.TabularData{
+		Headers: []string{"ID", "Comment"},
+		Records: [][]string{{"AE-R1-C1", "a"}, {"Editor", "b"}, {}, {"AE-R2-C1", "c"}},
+		Rows:    []int{2, 3, 5, 6},
+	}
+	scheme, err := common.ParseIDScheme("reviewer-comment")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := UnmatchedIDs(td, common.Options{IDScheme: scheme})
+	want := []string{`ID "Editor" in row 3 does not match the ID scheme reviewer-comment`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmatchedIDs() = %q; want %q", got, want)
+	}
+}
+
+func TestUnknownRefs(t *testing.T) {
+	td := &reader.TabularData{
+		Headers: []string{"ID", "Comment", "Response"},
+		Records: [][]string{
+			{"Rev1.1", "See Rev1.1 in Table 1.2", "as in Rev1.2"},
+			{"Rev1.2", "Rev1.9 and Rev2.1", "done"},
+		},
+	}
+
+	got := UnknownRefs(td, common.Options{})
+	want := []string{`row 3 refers to ID "Rev1.9", which does not exist`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("UnknownRefs() = %q; want %q", got, want)
+	}
+}
+
+func TestSupportsCustom(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"LaTeX", true},
+		{"Typst", true},
+		{"Markdown", true},
+		{"DOCX", false},
+		{"HTML", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SupportsCustom(mustNewTemplate(t, tt.name))
+			if got != tt.expected {
+				t.Errorf("SupportsCustom(%q) = %v; want %v", tt.name, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateCustom(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		text     string
+		wantErr  string
+	}{
+		{
+			name:     "Valid LaTeX",
+			template: "LaTeX",
+			text:     "{{ .Meta.Title }}{{ range .Rounds }}{{ range .Sections }}{{ .Name }}{{ end }}{{ end }}",
+		},
+		{
+			name:     "Valid Markdown",
+			template: "Markdown",
+			text:     "{{ range .Reviewers }}{{ .ReviewerID }}{{ end }}",
+		},
+		{
+			name:     "Built-in source",
+			template: "Typst",
+			text:     mustNewTemplate(t, "Typst").(CustomizableTemplate).Builtin(),
+		},
+		{
+			name:     "Parse error",
+			template: "LaTeX",
+			text:     "{{ range .Rounds }}",
+			wantErr:  "parsing latex template: template: house.tmpl:1: unexpected EOF",
+		},
+		{
+			name:     "Unknown field",
+			template: "Typst",
+			text:     "{{ .Reviewers }}",
+			wantErr:  "executing typst template: template: house.tmpl:1:3: executing \"house.tmpl\" at <.Reviewers>: can't evaluate field Reviewers",
+		},
+		{
+			name:     "Unsupported template",
+			template: "DOCX",
+			text:     "{{ .Meta.Title }}",
+			wantErr:  "custom templates are only supported for [LaTeX Typst Markdown HTML]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Lookup(tt.template)
+			if err != nil {
+				t.Fatalf("Lookup() error = %v", err)
+			}
+			err = ValidateCustom(context.Background(), f, &common.CustomTemplate{Name: "house.tmpl", Text: tt.text})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ValidateCustom() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateCustom() error = %v; want prefix %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRenderBytes_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, name := range Available() {
+		t.Run(name, func(t *testing.T) {
+			_, err := RenderBytes(ctx, mustNewTemplate(t, name), common.NewDocument(&sample), common.Options{})
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("RenderBytes() error = %v; want %v", err, context.Canceled)
+			}
+		})
+	}
+}
+
+func TestRenderBytes(t *testing.T) {
+	out, err := RenderBytes(context.Background(), mustNewTemplate(t, "Markdown"), common.NewDocument(&sample), common.Options{})
+	if err != nil {
+		t.Fatalf("RenderBytes() error = %v", err)
+	}
+	if !strings.Contains(string(out), "Rev1.1") {
+		t.Errorf("RenderBytes() = %q; want the responses of the document", out)
+	}
+}
+
+func TestRender_Empty(t *testing.T) {
+	tests := []struct {
+		name string
+		td   *reader.TabularData
+	}{
+		{"No data", &reader.TabularData{}},
+		{"Headers without records", &reader.TabularData{Headers: []string{"ID", "Comment", "Response"}}},
+		{"Records without headers", &reader.TabularData{Records: [][]string{{"Rev1.1", "c1", "r1"}, {".1", "c2", "r2"}}}},
+	}
+
+	for _, f := range Formats() {
+		for _, tt := range tests {
+			t.Run(f.Name+"/"+tt.name, func(t *testing.T) {
+				out, err := RenderBytes(context.Background(), f.New(), common.NewDocument(tt.td), common.Options{})
+				if err != nil {
+					t.Fatalf("Render() error = %v", err)
+				}
+				text := string(out)
+				if f.Binary {
+					text = zipText(t, out)
+				}
+				if !strings.Contains(text, "Dear Editor,") {
+					t.Errorf("Render() = %q; want the letter to the editor", text)
+				}
+				for _, section := range []string{"Rev1.1", "Reviewer 1", "Other Comments"} {
+					if strings.Contains(text, section) {
+						t.Errorf("Render() output contains %q; want no reviewer sections", section)
+					}
+				}
+			})
+		}
+	}
+}
+
+// zipText returns the concatenated content of the files of a zip archive, e.g., a DOCX package.
+func zipText(t *testing.T, data []byte) string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Render() is not a valid zip archive: %v", err)
+	}
+	var b strings.Builder
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = io.Copy(&b, rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return b.String()
+}
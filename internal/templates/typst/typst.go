@@ -1,12 +1,19 @@
 package typst
 
 import (
+	"context"
 	_ "embed"
 	"fmt"
-	"html/template"
+	"io"
+	"strconv"
 	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/andreas-bauer/rejoinderoo/internal/model"
 	"github.com/andreas-bauer/rejoinderoo/internal/reader"
+	"github.com/andreas-bauer/rejoinderoo/internal/templates/cellmarkup"
 	"github.com/andreas-bauer/rejoinderoo/internal/templates/common"
 )
 
@@ -20,13 +27,35 @@ type record struct {
 
 type response struct {
 	ID         string
+	Label      string
 	ReviewerID string
 	Records    []record
 }
 
+// section holds the responses to one reviewer.
+type section struct {
+	Name      string
+	Responses []response
+}
+
+// round holds the responses of one review round, rendered as its own section.
+// ColorPrefix keeps the reviewer colors and labels of different rounds apart.
+// SectionLevel is the heading level of the reviewer sections, which are nested below a named round.
+type round struct {
+	Name          string
+	ColorPrefix   string
+	StartAppendix bool
+	InAppendix    bool
+	Colors        []common.ReviewerColor
+	SectionLevel  int
+	CommentLevel  int
+	Sections      []section
+}
+
 type document struct {
-	ReviewerIDs []string
-	Responses   []response
+	Meta   common.Metadata
+	TOC    bool
+	Rounds []round
 }
 
 //go:embed typst.tmpl
@@ -41,81 +70,265 @@ func (t *Typst) FileExtension() string {
 	return ".typ"
 }
 
-// Render processes the Typst template with the provided tabular data.
-func (t *Typst) Render(td reader.TabularData) (string, error) {
+// Builtin returns the source of the built-in Typst template, e.g., as a starting point for a custom template.
+func (t *Typst) Builtin() string {
+	return file
+}
+
+// Render writes the Typst document with the responses of the document to w.
+// With several review rounds, the rounds are rendered in chronological order with a section per round.
+func (t *Typst) Render(ctx context.Context, w io.Writer, doc common.Document, opts common.Options) error {
+	if doc.Rounds != nil {
+		rounds, err := createRounds(*doc.Rounds, opts)
+		if err != nil {
+			return err
+		}
+		return render(ctx, w, rounds, opts)
+	}
+	td, err := doc.Single("Typst")
+	if err != nil {
+		return err
+	}
+	return render(ctx, w, []round{createRound(td, opts, "")}, opts)
+}
+
+// createRounds creates a section per review round. The sheets of the workbook are the review rounds
+// in chronological order.
+func createRounds(wb reader.Workbook, opts common.Options) ([]round, error) {
+	if len(wb.Sheets) == 0 {
+		return nil, fmt.Errorf("workbook does not contain any review rounds")
+	}
 
-	escapeAllStrings(&td)
-	doc := createDoc(&td)
+	order, appendixStart := common.RoundOrder(len(wb.Sheets), opts.AppendixRounds)
+	rounds := make([]round, len(order))
+	for pos, idx := range order {
+		sheet := wb.Sheets[idx]
+		r := createRound(sheet.Data, opts, fmt.Sprintf("Round%d", idx+1))
+		r.Name = escape(sheet.Name)
+		r.StartAppendix = pos == appendixStart
+		r.InAppendix = pos >= appendixStart
+		r.SectionLevel = 2
+		if r.InAppendix {
+			r.SectionLevel = 3
+		}
+		r.CommentLevel = r.SectionLevel + 1
+		rounds[pos] = r
+	}
+	return rounds, nil
+}
 
-	tmpl, err := template.New("typst").Parse(file)
+// render writes the rounds with the template of the options, or the built-in one, to w.
+// Rendering stops with the error of the context once it is done.
+func render(ctx context.Context, w io.Writer, rounds []round, opts common.Options) error {
+	doc := document{
+		Meta:   opts.Meta.Escaped(escaper(opts)),
+		TOC:    opts.TableOfContents,
+		Rounds: rounds,
+	}
 
+	name, text := opts.Template.Source("typst", file)
+	tmpl, err := template.New(name).Parse(text)
 	if err != nil {
-		fmt.Println(err.Error())
-		panic(err)
+		return fmt.Errorf("parsing typst template: %w", err)
 	}
 
-	var result strings.Builder
-	err = tmpl.Execute(&result, doc)
+	err = tmpl.Execute(common.NewContextWriter(ctx, w), doc)
 	if err != nil {
-		fmt.Println(err.Error())
-		panic(err)
+		return fmt.Errorf("executing typst template: %w", err)
+	}
+
+	return nil
+}
+
+// createRound groups the responses of the tabular data into a section per reviewer.
+// The prefix keeps the colors and labels of the round apart from those of other rounds.
+func createRound(td *reader.TabularData, opts common.Options, prefix string) round {
+	rj := model.New(td, opts)
+	// labels and cross-references are derived before escaping, which could break the ID scheme
+	labels := common.Labels(prefix, td.Records)
+	refs := common.NewCrossRefs(td.Records, labels, model.IDScheme(td, opts.IDScheme))
+
+	sections := make([]section, len(rj.Reviewers))
+	for i, rev := range rj.Reviewers {
+		sections[i].Name = escape(rev.Name)
+		for _, c := range rev.Comments {
+			res := asDocResponse(rj, c, opts, refs)
+			res.Label = labels[c.Index]
+			sections[i].Responses = append(sections[i].Responses, res)
+		}
 	}
 
-	return result.String(), nil
+	return round{
+		ColorPrefix:  prefix,
+		Colors:       opts.ColorDefs(rj.ReviewerIDs()),
+		SectionLevel: 1,
+		CommentLevel: 2,
+		Sections:     sections,
+	}
 }
 
-func createDoc(td *reader.TabularData) document {
-	allRevIDs := common.ExtractReviewers(td.Records)
-	responses := asDocResponses(td.Headers, td.Records)
+// asDocResponse converts a comment to a response with the comment and the details as records.
+// References to the IDs of other responses are linked in all records.
+func asDocResponse(rj model.Rejoinder, c model.Comment, opts common.Options, refs *common.CrossRefs) response {
+	esc := escaper(opts)
+	res := response{
+		ID:         escapeCell(c.ID, opts, nil),
+		ReviewerID: rj.ColorID(c.Reviewer),
+	}
+	for _, cell := range rj.Fields(c) {
+		res.Records = append(res.Records, record{
+			Header: esc(cell.Header),
+			Text:   escapeCell(cell, opts, refs.Of(c.Reviewer)),
+		})
+	}
+	return res
+}
 
-	return document{
-		ReviewerIDs: allRevIDs,
-		Responses:   responses,
+// escaper returns the escape function for the escaping mode of the options.
+func escaper(opts common.Options) func(string) string {
+	return func(s string) string {
+		return opts.Escaping.Escape(s, escape)
 	}
 }
 
-// asDocResponses converts a slice of strings to a slice of Response structs
-func asDocResponses(headers []string, records [][]string) []response {
-	var res = make([]response, len(records))
-	for idx, rec := range records {
-		if len(rec) < 1 {
+// escapeCell escapes the text of a cell and links the cross-references. Cells keep their
+// rich text formatting or, if enabled, have their cell markup converted.
+func escapeCell(c model.Cell, opts common.Options, refs *common.CrossRefs) string {
+	switch {
+	case c.Rich != nil:
+		return richText(c.Rich, opts.Escaping, refs)
+	case opts.CellMarkup:
+		text, restore := protect(c.Text, opts.Escaping, refs)
+		return restore(cellmarkup.Render(text, markupFormat))
+	default:
+		return escapeRefs(c.Text, opts.Escaping, refs, escape)
+	}
+}
+
+// richText converts the formatted runs of a cell to escaped Typst markup.
+// Typst's markup for strong and emphasized text only works at word boundaries,
+// so runs within a word use the equivalent functions instead.
+// Cross-references are linked, except within hyperlinks.
+func richText(rt reader.RichText, escaping common.Escaping, refs *common.CrossRefs) string {
+	esc := func(s string) string { return escapeRefs(s, escaping, refs, escape) }
+	var sb strings.Builder
+	for i, r := range rt {
+		lead, text, trail := common.SplitSpace(r.Text)
+		if text == "" || !r.Formatted() {
+			sb.WriteString(esc(r.Text))
 			continue
 		}
-		response := &response{
-			ID:         rec[0],
-			ReviewerID: common.ExtractReviewerID(rec[0]),
-			Records:    make([]record, len(headers)-1),
+
+		var prev, next string
+		if i > 0 && lead == "" {
+			prev = rt[i-1].Text
+		}
+		if i < len(rt)-1 && trail == "" {
+			next = rt[i+1].Text
+		}
+		inWord := endsWithWordChar(prev) || startsWithWordChar(next)
+
+		runRefs := refs
+		if r.Link != "" {
+			runRefs = nil // links cannot be nested
+		}
+		text = escapeRefs(text, escaping, runRefs, func(s string) string { return escapeMarkup(escape(s)) })
+		if r.Bold {
+			text = wrap(text, "*", "strong", inWord)
+		}
+		if r.Italic {
+			text = wrap(text, "_", "emph", inWord)
+		}
+		if r.Underline {
+			text = "#underline[" + text + "]"
+		}
+		if r.Link != "" {
+			text = "#link(" + strconv.Quote(r.Link) + ")[" + text + "]"
 		}
-		for i, h := range headers {
-			if i == 0 {
-				continue // skip ID
-			}
-			var text string
-			if i < len(rec) {
-				text = rec[i]
-			}
-			record := &record{
-				Header: h,
-				Text:   text,
-			}
-			response.Records[i-1] = *record
+		if strings.HasPrefix(text, "#") && next != "" && strings.ContainsRune(".([", rune(next[0])) {
+			// end the function call, e.g., before a period directly following a link
+			text += ";"
 		}
-		res[idx] = *response
+		sb.WriteString(esc(lead) + text + esc(trail))
 	}
-	return res
+	return sb.String()
+}
 
+// protect replaces what the escaping mode passes through, as well as cross-references,
+// with placeholders, see common.Escaping.Protect.
+func protect(text string, escaping common.Escaping, refs *common.CrossRefs) (string, func(string) string) {
+	protected, restore := escaping.Protect(text, escape)
+	protected, restoreRefs := refs.Protect(protected, refFormat)
+	return protected, func(s string) string { return restore(restoreRefs(s)) }
 }
 
-func escapeAllStrings(td *reader.TabularData) {
-	for i, h := range td.Headers {
-		td.Headers[i] = escape(h)
-	}
+// escapeRefs escapes the text with the given function, except for what the escaping mode passes through,
+// and links the cross-references to their responses.
+func escapeRefs(text string, escaping common.Escaping, refs *common.CrossRefs, escapeFn func(string) string) string {
+	protected, restore := protect(text, escaping, refs)
+	return restore(escapeFn(protected))
+}
+
+// refFormat links cross-references to the labels of the responses.
+var refFormat = common.RefFormat{
+	Escape:  func(text string) string { return escapeMarkup(escape(text)) },
+	Link:    func(text, label string) string { return "#link(<" + label + ">)[" + text + "]" },
+	CallEnd: ";",
+}
 
-	for i, rec := range td.Records {
-		for j, r := range rec {
-			td.Records[i][j] = escape(r)
+// markupFormat converts cell markup to Typst.
+var markupFormat = cellmarkup.Format{
+	Escape: func(text string) string { return escapeMarkup(escape(text)) },
+	Strong: func(content string) string { return "*" + content + "*" },
+	Emph:   func(content string) string { return "_" + content + "_" },
+	Code: func(code string) string {
+		if strings.Contains(code, "`") {
+			return "#raw(" + strconv.Quote(code) + ")"
+		}
+		return "`" + code + "`"
+	},
+	Link: func(url, content string) string {
+		return "#link(" + strconv.Quote(url) + ")[" + content + "]"
+	},
+	List: func(ordered bool, items []string) string {
+		marker := "- "
+		if ordered {
+			marker = "+ "
 		}
+		// lists need to start on a new line, e.g., after the header of a record
+		var sb strings.Builder
+		for _, item := range items {
+			sb.WriteString("\n" + marker + item)
+		}
+		return sb.String()
+	},
+	CallEnd: ";",
+}
+
+// wrap marks the text with the markup delimiter or, within a word, with the function.
+func wrap(text, delim, function string, inWord bool) string {
+	if inWord {
+		return "#" + function + "[" + text + "]"
 	}
+	return delim + text + delim
+}
+
+// escapeMarkup escapes the delimiters of strong and emphasized text.
+func escapeMarkup(input string) string {
+	return strings.NewReplacer(
+		"*", "\\*",
+		"_", "\\_",
+	).Replace(input)
+}
+
+func endsWithWordChar(s string) bool {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func startsWithWordChar(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
 }
 
 // Escape escapes special characters for Typst.
@@ -128,6 +341,7 @@ func escape(input string) string {
 		"[", "\\[",
 		"]", "\\]",
 		"#", "\\#",
+		"$", "\\$",
 	)
 	return replacer.Replace(input)
 }
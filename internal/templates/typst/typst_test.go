@@ -1,10 +1,16 @@
 package typst
 
 import (
+	"context"
+	"strings"
 	"testing"
+
+	"github.com/andreas-bauer/rejoinderoo/internal/model"
+	"github.com/andreas-bauer/rejoinderoo/internal/reader"
+	"github.com/andreas-bauer/rejoinderoo/internal/templates/common"
 )
 
-func TestAsDocresponses(t *testing.T) {
+func TestAsDocResponse(t *testing.T) {
 	tests := []struct {
 		name     string
 		headers  []string
@@ -55,7 +61,7 @@ func TestAsDocresponses(t *testing.T) {
 			},
 		},
 		{
-			name:    "Contains empty records",
+			name:    "Skips empty records",
 			headers: []string{"ID", "Comment", "response"},
 			records: [][]string{
 				{"Rev2.4", "Another comment", "Another response"},
@@ -70,10 +76,6 @@ func TestAsDocresponses(t *testing.T) {
 						{Header: "response", Text: "Another response"},
 					},
 				},
-				{
-					ReviewerID: "",
-					Records:    []record{},
-				},
 			},
 		},
 		{
@@ -92,30 +94,29 @@ func TestAsDocresponses(t *testing.T) {
 						{Header: "Response", Text: ""},
 					},
 				},
-				{
-					ReviewerID: "",
-					Records:    []record{},
-				},
 			},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := asDocResponses(tt.headers, tt.records)
-			if len(result) != len(tt.expected) {
-				t.Errorf("asDocresponses() length = %d; want %d", len(result), len(tt.expected))
+			rj := model.New(&reader.TabularData{Headers: tt.headers, Records: tt.records}, common.Options{})
+			comments := rj.Comments()
+			if len(comments) != len(tt.expected) {
+				t.Fatalf("comments length = %d; want %d", len(comments), len(tt.expected))
 			}
-			for i, res := range result {
-				if res.ReviewerID != tt.expected[i].ReviewerID {
-					t.Errorf("asDocresponses()[%d].ReviewerID = %q; want %q", i, res.ReviewerID, tt.expected[i].ReviewerID)
+			for i, c := range comments {
+				res := asDocResponse(rj, c, common.Options{}, nil)
+				if res.ID != tt.expected[i].ID || res.ReviewerID != tt.expected[i].ReviewerID {
+					t.Errorf("asDocResponse()[%d] = %q of %q; want %q of %q", i, res.ID, res.ReviewerID, tt.expected[i].ID, tt.expected[i].ReviewerID)
 				}
 				if len(res.Records) != len(tt.expected[i].Records) {
-					t.Errorf("asDocresponses()[%d].Records length = %d; want %d", i, len(res.Records), len(tt.expected[i].Records))
+					t.Errorf("asDocResponse()[%d].Records length = %d; want %d", i, len(res.Records), len(tt.expected[i].Records))
+					continue
 				}
 				for j, rec := range res.Records {
-					if rec.Header != tt.expected[i].Records[j].Header || rec.Text != tt.expected[i].Records[j].Text {
-						t.Errorf("asDocresponses()[%d].Records[%d] = %+v; want %+v", i, j, rec, tt.expected[i].Records[j])
+					if rec != tt.expected[i].Records[j] {
+						t.Errorf("asDocResponse()[%d].Records[%d] = %+v; want %+v", i, j, rec, tt.expected[i].Records[j])
 					}
 				}
 			}
@@ -144,6 +145,7 @@ func TestEscape(t *testing.T) {
 		{"[", "\\["},
 		{"]", "\\]"},
 		{"#", "\\#"},
+		{"$5", "\\$5"},
 		{"\\{[#]}", "\\\\\\{\\[\\#\\]\\}"},
 		{"Hello #1 [test] {ok}", "Hello \\#1 \\[test\\] \\{ok\\}"},
 		{"multiple \\# special {chars}", "multiple \\\\\\# special \\{chars\\}"},
@@ -159,3 +161,370 @@ func TestEscape(t *testing.T) {
 		})
 	}
 }
+
+func TestRenderMetadata(t *testing.T) {
+	td := reader.TabularData{
+		Headers: []string{"ID", "Comment", "Response"},
+		Records: [][]string{{"Rev1.1", "Comment \"quoted\"", "A & B"}},
+	}
+	meta := common.Metadata{
+		Title:        "Tests & #Reviews",
+		ManuscriptID: "EMSE-42",
+		Authors:      []string{"A. Bauer", "M. Doe"},
+		Editor:       "Prof. Smith",
+		KeyChanges:   []string{"Switched [Tables] 3 and 4"},
+	}
+
+	out, err := renderString(common.NewDocument(&td), common.Options{Meta: meta})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"= Tests & \\#Reviews\n",
+		"    EMSE-42\n",
+		"    A. Bauer, M. Doe\n",
+		"Dear Prof. Smith,",
+		"- Switched \\[Tables\\] 3 and 4",
+		"*Comment*: Comment \"quoted\"",
+		"*Response*: A & B",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Render() output does not contain %q", want)
+		}
+	}
+}
+
+func TestRenderRounds(t *testing.T) {
+	wb := reader.Workbook{Sheets: []reader.Sheet{
+		{Name: "Round 1", Data: &reader.TabularData{
+			Headers: []string{"ID", "Comment", "Response"},
+			Records: [][]string{{"Rev1.1", "First comment", "First response"}},
+		}},
+		{Name: "Round #2", Data: &reader.TabularData{
+			Headers: []string{"ID", "Comment", "Response"},
+			Records: [][]string{{"Rev1.1", "Second comment", "Second response"}},
+		}},
+	}}
+
+	tests := []struct {
+		name     string
+		appendix bool
+		expected []string
+	}{
+		{
+			name: "rounds in order",
+			expected: []string{
+				"#let colorRound1Rev1 = ",
+				"#let colorRound2Rev1 = ",
+				"\n= Round 1\n",
+				"#heading(level: 2)[Reviewer 1]",
+				"<Round1:Rev1.1>",
+				"color: colorRound1Rev1,",
+				"\n= Round \\#2\n",
+				"<Round2:Rev1.1>",
+				"color: colorRound2Rev1,",
+			},
+		},
+		{
+			name:     "earlier rounds in appendix",
+			appendix: true,
+			expected: []string{
+				"\n= Round \\#2\n",
+				"\n= Appendix: Earlier Review Rounds\n\n== Round 1\n",
+				"#heading(level: 3)[Reviewer 1]",
+				"#heading(level: 4, outlined: false, bookmarked: true)[Rev1.1] <Round1:Rev1.1>",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := renderString(common.NewRoundsDocument(&wb), common.Options{AppendixRounds: tt.appendix})
+			if err != nil {
+				t.Fatalf("RenderRounds() error = %v", err)
+			}
+			for _, want := range tt.expected {
+				if !strings.Contains(out, want) {
+					t.Errorf("RenderRounds() output does not contain %q", want)
+				}
+			}
+		})
+	}
+}
+
+func TestRichText(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    reader.RichText
+		expected string
+	}{
+		{
+			name:     "plain runs",
+			input:    reader.RichText{{Text: "No #formatting"}},
+			expected: "No \\#formatting",
+		},
+		{
+			name:     "bold and italic at word boundaries",
+			input:    reader.RichText{{Text: "We "}, {Text: "fixed ", Bold: true}, {Text: "all_of", Italic: true}, {Text: " it"}},
+			expected: "We *fixed* _all\\_of_ it",
+		},
+		{
+			name:     "bold within a word",
+			input:    reader.RichText{{Text: "Rev", Bold: true}, {Text: "iewer"}},
+			expected: "#strong[Rev]iewer",
+		},
+		{
+			name:     "underline",
+			input:    reader.RichText{{Text: "important", Underline: true}},
+			expected: "#underline[important]",
+		},
+		{
+			name:     "link followed by period",
+			input:    reader.RichText{{Text: "See "}, {Text: "docs", Link: "https://example.com/?q=\"a\""}, {Text: "."}},
+			expected: "See #link(\"https://example.com/?q=\\\"a\\\"\")[docs];.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := richText(tt.input, common.StrictEscaping, nil)
+			if got != tt.expected {
+				t.Errorf("richText() = %q; want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRenderCellMarkup(t *testing.T) {
+	td := reader.TabularData{
+		Headers: []string{"ID", "Comment", "Response"},
+		Records: [][]string{{"Rev1.1", "Use **bold** and `code`", "Changes:\n1. See [docs](https://example.com).\n2. snake_case"}},
+	}
+
+	out, err := renderString(common.NewDocument(&td), common.Options{CellMarkup: true})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"*Comment*: Use *bold* and `code`",
+		"*Response*: Changes:\n\n\n+ See #link(\"https://example.com\")[docs];.\n+ snake\\_case",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Render() output does not contain %q", want)
+		}
+	}
+}
+
+func TestRenderEscaping(t *testing.T) {
+	td := reader.TabularData{
+		Headers: []string{"ID", "Comment", "Response"},
+		Records: [][]string{{"Rev1.1", "Is $p < 0.05$ for #1?", "See {{raw}}#cite(<doe>){{/raw}}, it costs $5"}},
+	}
+
+	tests := []struct {
+		name     string
+		escaping common.Escaping
+		expected []string
+	}{
+		{
+			name:     "strict",
+			escaping: common.StrictEscaping,
+			expected: []string{"Is \\$p < 0.05\\$ for \\#1?", "See \\{\\{raw\\}\\}\\#cite(<doe>)\\{\\{/raw\\}\\}, it costs \\$5"},
+		},
+		{
+			name:     "math-aware",
+			escaping: common.MathEscaping,
+			expected: []string{"Is $p < 0.05$ for \\#1?", "See #cite(<doe>), it costs \\$5"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := renderString(common.NewDocument(&td), common.Options{Escaping: tt.escaping})
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			for _, want := range tt.expected {
+				if !strings.Contains(out, want) {
+					t.Errorf("Render() output does not contain %q", want)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderIDScheme(t *testing.T) {
+	td := reader.TabularData{
+		Headers: []string{"ID", "Comment", "Response"},
+		Records: [][]string{
+			{"AE-R2-C14", "First comment", "First response"},
+			{"Editor", "Second comment", "Second response"},
+		},
+	}
+	scheme, err := common.ParseIDScheme("reviewer-comment")
+	if err != nil {
+		t.Fatal(err)
+	}
+	verbose, err := common.ParseIDScheme("verbose")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := renderString(common.NewDocument(&td), common.Options{IDScheme: scheme})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	for _, want := range []string{`#let colorAER2 = rgb("#F2CF80")`, "color: colorAER2,", "color: colorRevDefault,"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Render() output does not contain %q", want)
+		}
+	}
+
+	td.Records = [][]string{{"Reviewer #3 / Comment 1", "Comment", "Response"}}
+	out, err = renderString(common.NewDocument(&td), common.Options{IDScheme: verbose})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !strings.Contains(out, `#let colorReviewer3 = rgb("#F2CF80")`) {
+		t.Errorf("Render() output does not contain %q", `#let colorReviewer3 = rgb("#F2CF80")`)
+	}
+}
+
+func TestRenderDistinctColors(t *testing.T) {
+	td := reader.TabularData{
+		Headers: []string{"ID", "Comment", "Response"},
+		Records: [][]string{{"R-1.1", "c1", "r1"}, {"R1.1", "c2", "r2"}},
+	}
+	scheme, err := common.ParseIDScheme(`^(?P<reviewer>.+)\.(?P<comment>\d+)$`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := renderString(common.NewDocument(&td), common.Options{IDScheme: scheme})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	for _, want := range []string{"#let colorR1 = ", "#let colorR12 = ", "color: colorR1,", "color: colorR12,"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Render() output does not contain %q", want)
+		}
+	}
+}
+
+func TestRenderSections(t *testing.T) {
+	td := reader.TabularData{
+		Headers: []string{"ID", "Comment", "Response"},
+		Records: [][]string{
+			{"ED.1", "Editor comment", "Editor response"},
+			{"Rev1.1", "First comment", "First response"},
+			{"ED.2", "Second editor comment", "Second editor response"},
+		},
+	}
+
+	out, err := renderString(common.NewDocument(&td), common.Options{})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	expected := []string{
+		"#heading(level: 1)[Editor]",
+		"#place(hide[#heading(level: 2, outlined: false, bookmarked: true)[ED.1] <ED.1>])",
+		"#place(hide[#heading(level: 2, outlined: false, bookmarked: true)[ED.2] <ED.2>])",
+		"#heading(level: 1)[Reviewer 1]",
+		"#place(hide[#heading(level: 2, outlined: false, bookmarked: true)[Rev1.1] <Rev1.1>])",
+	}
+	last := -1
+	for _, want := range expected {
+		idx := strings.Index(out, want)
+		if idx < 0 {
+			t.Fatalf("Render() output does not contain %q", want)
+		}
+		if idx < last {
+			t.Errorf("Render() output contains %q out of order", want)
+		}
+		last = idx
+	}
+	if strings.Contains(out, "#outline()") {
+		t.Errorf("Render() output contains a table of contents without TableOfContents")
+	}
+
+	out, err = renderString(common.NewDocument(&td), common.Options{TableOfContents: true})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !strings.Contains(out, "#outline()") {
+		t.Errorf("Render() output does not contain a table of contents")
+	}
+}
+
+func TestRenderCrossRefs(t *testing.T) {
+	td := reader.TabularData{
+		Headers: []string{"ID", "Comment", "Response"},
+		Records: [][]string{
+			{"Rev1.1", "First comment", "See our answer to Rev_2.1."},
+			{"Rev_2.1", "Second comment", "As for Rev1.1[1] and Rev_2.1"},
+		},
+		Rich: [][]reader.RichText{nil, {nil, {{Text: "Rev1.1", Bold: true}, {Text: " and "}, {Text: "Rev1.1", Link: "https://example.com"}}}},
+	}
+
+	out, err := renderString(common.NewDocument(&td), common.Options{})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	for _, want := range []string{
+		"See our answer to #link(<Rev_2.1>)[Rev\\_2.1];.",
+		"As for #link(<Rev1.1>)[Rev1.1];\\[1\\] and #link(<Rev_2.1>)[Rev\\_2.1]",
+		"*#link(<Rev1.1>)[Rev1.1]* and #link(\"https://example.com\")[Rev1.1]",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Render() output does not contain %q", want)
+		}
+	}
+}
+
+func TestRenderRoles(t *testing.T) {
+	td := reader.TabularData{
+		Headers: []string{"Status", "Response", "No", "Reviewer", "Remark"},
+		Records: [][]string{
+			{"done", "We fixed it.", "1", "R2", "Typo in Sec. 2"},
+		},
+		Roles: []reader.Role{reader.HiddenRole, reader.NoRole, reader.IDRole, reader.ReviewerRole, reader.CommentRole},
+	}
+	td.Keep(td.Headers)
+
+	out, err := renderString(common.NewDocument(&td), common.Options{})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	expected := []string{
+		"#heading(level: 1)[Reviewer 2]",
+		"color: colorR2,",
+		"ref: [ ID: 1 ],",
+		"*Remark*: Typo in Sec. 2",
+		"*Response*: We fixed it.",
+	}
+	last := -1
+	for _, want := range expected {
+		idx := strings.Index(out, want)
+		if idx < 0 {
+			t.Fatalf("Render() output does not contain %q", want)
+		}
+		if idx < last {
+			t.Errorf("Render() output contains %q out of order", want)
+		}
+		last = idx
+	}
+	for _, unwanted := range []string{"done", "*Status*", "*Reviewer*"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("Render() output contains the hidden or reviewer column %q", unwanted)
+		}
+	}
+}
+
+// renderString renders the document with the typst template into a string.
+func renderString(doc common.Document, opts common.Options) (string, error) {
+	var b strings.Builder
+	err := NewTypstTemplate().Render(context.Background(), &b, doc, opts)
+	return b.String(), err
+}
@@ -4,7 +4,11 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/andreas-bauer/rejoinderoo/internal/filter"
+	"github.com/andreas-bauer/rejoinderoo/internal/order"
 	"github.com/andreas-bauer/rejoinderoo/internal/templates"
+	"github.com/andreas-bauer/rejoinderoo/internal/templates/common"
+	"github.com/andreas-bauer/rejoinderoo/rejoinder"
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -14,6 +18,12 @@ type FormData struct {
 	AvailableHeaders []string
 	SelectedHeaders  []string
 	Template         string
+	Palette          string
+	Order            string
+	Where            string
+	Roles            map[string]rejoinder.Role
+	PDF              bool
+	PDFFilename      string
 }
 
 func RunFilePicker() string {
@@ -31,7 +41,69 @@ func RunFilePicker() string {
 	return file
 }
 
+// RunSheetPicker lets the user select one of the given sheets of a workbook.
+func RunSheetPicker(sheets []string) (string, error) {
+	var sheet string
+	err := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().Title("Sheet").
+				Description("Select the sheet with the review comments").
+				Options(huh.NewOptions(sheets...)...).
+				Value(&sheet),
+		),
+	).Run()
+	return sheet, err
+}
+
+// roleFields are the roles that can be assigned in the form, except for hidden columns.
+var roleFields = []struct {
+	role        rejoinder.Role
+	title       string
+	description string
+}{
+	{rejoinder.IDRole, "ID column", "Default: the first column without a role"},
+	{rejoinder.ReviewerRole, "Reviewer column", "Default: the reviewer is taken from the ID"},
+	{rejoinder.CommentRole, "Comment column", "Default: the first other column without a role"},
+	{rejoinder.ResponseRole, "Response column", "Shown first below the comment"},
+	{rejoinder.ActionRole, "Action column", "Shown after the response"},
+	{rejoinder.LocationRole, "Location column", "Shown after the action"},
+}
+
 func RunForm(fd *FormData) error {
+	ord, _ := order.Parse(fd.Order)
+	mode := ord.Mode.String()
+	reviewers := strings.Join(ord.Reviewers, ", ")
+
+	// the selected column of each role, or an empty string for the default
+	roleColumns := make(map[rejoinder.Role]*string)
+	var hidden []string
+	for h, r := range fd.Roles {
+		if r == rejoinder.HiddenRole {
+			hidden = append(hidden, h)
+		} else if r != rejoinder.NoRole {
+			roleColumns[r] = &h
+		}
+	}
+	columnOptions := func() []huh.Option[string] {
+		return append([]huh.Option[string]{huh.NewOption("(default)", "")}, huh.NewOptions(fd.SelectedHeaders...)...)
+	}
+	var roles []huh.Field
+	for _, f := range roleFields {
+		if roleColumns[f.role] == nil {
+			roleColumns[f.role] = new(string)
+		}
+		roles = append(roles, huh.NewSelect[string]().Title(f.title).
+			Description(f.description).
+			OptionsFunc(columnOptions, &fd.SelectedHeaders).
+			Value(roleColumns[f.role]))
+	}
+	roles = append(roles, huh.NewMultiSelect[string]().Title("Hidden columns").
+		Description("Columns that are kept, e.g., for filtering, but not shown").
+		OptionsFunc(func() []huh.Option[string] {
+			return huh.NewOptions(fd.SelectedHeaders...)
+		}, &fd.SelectedHeaders).
+		Value(&hidden))
+
 	form := huh.NewForm(
 		huh.NewGroup(
 			huh.NewMultiSelect[string]().Title("Select Columns").
@@ -46,12 +118,57 @@ func RunForm(fd *FormData) error {
 				}).
 				Value(&fd.SelectedHeaders),
 		),
+		huh.NewGroup(roles...),
 		huh.NewGroup(
 			huh.NewSelect[string]().Title("Template").
 				Description("Select the output template for the rejoinder").
-				Options(huh.NewOptions(templates.Available()...)...).
+				Options(templateOptions()...).
 				Value(&fd.Template),
 		),
+		huh.NewGroup(
+			huh.NewSelect[string]().Title("Color palette").
+				Description("Select the colors that tell the reviewers apart").
+				Options(huh.NewOptions(common.PaletteNames()...)...).
+				Value(&fd.Palette),
+		).WithHideFunc(func() bool {
+			return !supports(fd.Template, templates.OptionPalette)
+		}),
+		huh.NewGroup(
+			huh.NewSelect[string]().Title("Order").
+				Description("Select the order of the responses").
+				Options(huh.NewOptions(order.Modes()...)...).
+				Value(&mode),
+		),
+		huh.NewGroup(
+			huh.NewSelect[string]().Title("Sort column").
+				Description("Select the column to sort the responses by").
+				Options(huh.NewOptions(fd.AvailableHeaders...)...).
+				Value(&ord.Column),
+		).WithHideFunc(func() bool {
+			return mode != order.Column.String()
+		}),
+		huh.NewGroup(
+			huh.NewInput().Title("Reviewer order").
+				Description("Comma-separated reviewers to put first, e.g., Editor, R2, R1").
+				Prompt("> ").
+				Validate(func(s string) error {
+					_, err := order.Parse(order.Reviewers.String() + "=" + s)
+					return err
+				}).
+				Value(&reviewers),
+		).WithHideFunc(func() bool {
+			return mode != order.Reviewers.String()
+		}),
+		huh.NewGroup(
+			huh.NewInput().Title("Row filter").
+				Description("Optionally keep only matching rows, e.g., Status!=done or Responsible in (Andreas, Maria)").
+				Prompt("> ").
+				Validate(func(s string) error {
+					_, err := filter.Parse(s)
+					return err
+				}).
+				Value(&fd.Where),
+		),
 		huh.NewGroup(
 			huh.NewInput().Title("Filename").
 				Description("The file name of the generated rejoinder").
@@ -59,6 +176,13 @@ func RunForm(fd *FormData) error {
 				Placeholder("output.tex").
 				Value(&fd.Filename),
 		),
+		huh.NewGroup(
+			huh.NewConfirm().Title("Compile to PDF?").
+				Description("Requires latexmk, pdflatex, lualatex, or typst to be installed").
+				Value(&fd.PDF),
+		).WithHideFunc(func() bool {
+			return !supports(fd.Template, templates.OptionPDF)
+		}),
 	)
 
 	err := form.Run()
@@ -66,19 +190,57 @@ func RunForm(fd *FormData) error {
 		return err
 	}
 
+	fd.Roles = make(map[string]rejoinder.Role)
+	for _, h := range hidden {
+		fd.Roles[h] = rejoinder.HiddenRole
+	}
+	for _, f := range roleFields {
+		if h := *roleColumns[f.role]; h != "" {
+			fd.Roles[h] = f.role
+		}
+	}
+
+	switch mode {
+	case order.Column.String():
+		fd.Order = mode + "=" + ord.Column
+	case order.Reviewers.String():
+		fd.Order = mode + "=" + reviewers
+	default:
+		fd.Order = mode
+	}
 	return nil
 }
 
+// templateOptions returns the registered templates as options of a select with their descriptions.
+func templateOptions() []huh.Option[string] {
+	var opts []huh.Option[string]
+	for _, f := range templates.Formats() {
+		opts = append(opts, huh.NewOption(fmt.Sprintf("%s – %s", f.Name, f.Description), f.Name))
+	}
+	return opts
+}
+
+// supports reports whether the template with the given name takes the option into account.
+func supports(name string, opt templates.Option) bool {
+	f, err := templates.Lookup(name)
+	return err == nil && f.Supports(opt)
+}
+
 func PrintSummary(fd *FormData) {
 	var sb strings.Builder
 	keyword := func(s string) string {
 		return lipgloss.NewStyle().Foreground(lipgloss.Color("212")).Render(s)
 	}
+	var pdf string
+	if fd.PDFFilename != "" {
+		pdf = fmt.Sprintf("PDF: %s\n", keyword(fd.PDFFilename))
+	}
 	fmt.Fprintf(&sb,
-		"%s\n\nTempate: %s\nFilename: %s\n\n%s\n%s",
+		"%s\n\nTempate: %s\nFilename: %s\n%s\n%s\n%s",
 		lipgloss.NewStyle().Bold(true).Render("✅ Rejoinder created"),
 		keyword(fd.Template),
 		keyword(fd.Filename),
+		pdf,
 		"⭐️ If you enjoy this project, please consider giving it a star on GitHub:",
 		keyword("   https://github.com/andreas-bauer/rejoinderoo"),
 	)
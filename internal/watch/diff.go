@@ -0,0 +1,87 @@
+package watch
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Response is a rendered response, identified by its ID, with the texts of its parts,
+// e.g., the reviewer, the comment, and the response.
+type Response struct {
+	ID    string
+	Parts []string
+}
+
+// Snapshot holds the rendered content of each response, identified by its ID.
+type Snapshot struct {
+	ids     []string
+	content map[string]string
+}
+
+// NewSnapshot creates a snapshot of the responses. Responses without an ID are skipped.
+func NewSnapshot(responses []Response) Snapshot {
+	s := Snapshot{content: make(map[string]string)}
+	for _, r := range responses {
+		if r.ID == "" {
+			continue
+		}
+		if _, ok := s.content[r.ID]; !ok {
+			s.ids = append(s.ids, r.ID)
+		}
+		s.content[r.ID] = strings.Join(r.Parts, "\x00")
+	}
+	return s
+}
+
+// Changes lists the IDs of responses that differ between two snapshots.
+type Changes struct {
+	Added   []string
+	Removed []string
+	Changed []string
+}
+
+// Diff compares the previous snapshot with the current one.
+func Diff(prev, cur Snapshot) Changes {
+	var c Changes
+	for _, id := range cur.ids {
+		old, ok := prev.content[id]
+		switch {
+		case !ok:
+			c.Added = append(c.Added, id)
+		case old != cur.content[id]:
+			c.Changed = append(c.Changed, id)
+		}
+	}
+	for _, id := range prev.ids {
+		if _, ok := cur.content[id]; !ok {
+			c.Removed = append(c.Removed, id)
+		}
+	}
+	return c
+}
+
+// Empty reports whether there are no changes.
+func (c Changes) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Changed) == 0
+}
+
+// String returns a concise summary, e.g., "changed: Rev1.2, Rev2.1; added: Rev3.4".
+func (c Changes) String() string {
+	if c.Empty() {
+		return "no response changed"
+	}
+	var parts []string
+	for _, p := range []struct {
+		label string
+		ids   []string
+	}{
+		{"changed", c.Changed},
+		{"added", c.Added},
+		{"removed", c.Removed},
+	} {
+		if len(p.ids) > 0 {
+			parts = append(parts, fmt.Sprintf("%s: %s", p.label, strings.Join(p.ids, ", ")))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,78 @@
+package watch
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultDebounce is the time to wait for further changes before a change is reported.
+const DefaultDebounce = 500 * time.Millisecond
+
+// Watcher reports changes of a single file. Rapid successive writes,
+// as done by spreadsheet applications when saving, are reported once.
+type Watcher struct {
+	path     string
+	debounce time.Duration
+}
+
+// New creates a watcher for the file at the given path.
+func New(path string, debounce time.Duration) *Watcher {
+	return &Watcher{
+		path:     filepath.Clean(path),
+		debounce: debounce,
+	}
+}
+
+// Run watches the file until the context is canceled and calls onChange after each change.
+// The directory of the file is watched instead of the file itself, because
+// spreadsheet applications often save by replacing the file.
+// Errors of the underlying file system watcher are passed to onError.
+func (w *Watcher) Run(ctx context.Context, onChange func(), onError func(error)) error {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer fsw.Close()
+
+	if err := fsw.Add(filepath.Dir(w.path)); err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(w.debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-fsw.Events:
+			if !ok {
+				return nil
+			}
+			if w.relevant(event) {
+				timer.Reset(w.debounce)
+			}
+		case err, ok := <-fsw.Errors:
+			if !ok {
+				return nil
+			}
+			onError(err)
+		case <-timer.C:
+			onChange()
+		}
+	}
+}
+
+// relevant reports whether the event modifies the watched file.
+// Events for other files in the directory are ignored, e.g., the lock files that
+// Excel ("~$name.xlsx") and LibreOffice (".~lock.name.xlsx#") create while editing.
+func (w *Watcher) relevant(event fsnotify.Event) bool {
+	if filepath.Clean(event.Name) != w.path {
+		return false
+	}
+	return event.Has(fsnotify.Write) || event.Has(fsnotify.Create) || event.Has(fsnotify.Rename)
+}
@@ -0,0 +1,94 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	prev := NewSnapshot([]Response{
+		{ID: "Rev1.1", Parts: []string{"Comment", "Response"}},
+		{ID: "Rev1.2", Parts: []string{"Comment", "Response"}},
+		{ID: "Rev2.1", Parts: []string{"Comment", "Response"}},
+		{},
+	})
+	cur := NewSnapshot([]Response{
+		{ID: "Rev1.1", Parts: []string{"Comment", "Response"}},
+		{ID: "Rev1.2", Parts: []string{"Comment", "Updated response"}},
+		{ID: "Rev3.1", Parts: []string{"Comment", "Response"}},
+	})
+
+	got := Diff(prev, cur)
+	want := Changes{
+		Added:   []string{"Rev3.1"},
+		Removed: []string{"Rev2.1"},
+		Changed: []string{"Rev1.2"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %+v; want %+v", got, want)
+	}
+	if got.String() != "changed: Rev1.2; added: Rev3.1; removed: Rev2.1" {
+		t.Errorf("String() = %q", got.String())
+	}
+}
+
+func TestDiff_NoChanges(t *testing.T) {
+	responses := []Response{{ID: "Rev1.1", Parts: []string{"Comment"}}}
+	got := Diff(NewSnapshot(responses), NewSnapshot(responses))
+	if !got.Empty() {
+		t.Errorf("Diff() of equal snapshots = %+v; want no changes", got)
+	}
+	if got.String() != "no response changed" {
+		t.Errorf("String() = %q", got.String())
+	}
+}
+
+func TestWatcher_DebouncesAndIgnoresOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "reviews.csv")
+	if err := os.WriteFile(path, []byte("ID\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := make(chan struct{}, 10)
+	done := make(chan error, 1)
+	w := New(path, 100*time.Millisecond)
+	go func() {
+		done <- w.Run(ctx, func() { changes <- struct{}{} }, func(err error) { t.Error(err) })
+	}()
+	time.Sleep(100 * time.Millisecond) // wait for the watcher to be set up
+
+	// lock file of LibreOffice is ignored
+	if err := os.WriteFile(filepath.Join(dir, ".~lock.reviews.csv#"), []byte("lock"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// rapid saves are reported once
+	for i := range 3 {
+		if err := os.WriteFile(path, []byte("ID\nRev1."+string(rune('1'+i))+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	select {
+	case <-changes:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected a change to be reported")
+	}
+	select {
+	case <-changes:
+		t.Error("expected rapid saves to be reported once")
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Run() error = %v", err)
+	}
+}
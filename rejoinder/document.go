@@ -0,0 +1,248 @@
+package rejoinder
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/andreas-bauer/rejoinderoo/internal/model"
+	"github.com/andreas-bauer/rejoinderoo/internal/reader"
+	"github.com/andreas-bauer/rejoinderoo/internal/templates"
+	"github.com/andreas-bauer/rejoinderoo/internal/templates/common"
+)
+
+// Comment is a reviewer's comment together with the authors' response.
+type Comment struct {
+	// ID identifies the comment, e.g., "R1.2". The ID scheme of the options extracts the reviewer from it.
+	ID string
+	// Reviewer names the reviewer of the comment. If set, it takes precedence over the ID scheme.
+	Reviewer string
+	// Comment is the reviewer's comment.
+	Comment string
+	// Response is the authors' response.
+	Response string
+	// Fields are further columns that are rendered below the response, e.g., the action taken.
+	Fields []Field
+	// Round names the review round of the comment, e.g., "Round 2". Empty for documents without rounds.
+	Round string
+	// Row is the 1-based row of the comment in the input file, or 0 if unknown.
+	Row int
+}
+
+// Field is a further column of a comment.
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Column headers of documents built with NewDocument.
+const (
+	HeaderID       = "ID"
+	HeaderReviewer = "Reviewer"
+	HeaderComment  = "Comment"
+	HeaderResponse = "Response"
+)
+
+// Document holds the comments of a rejoinder, either of a single sheet or of several review rounds.
+// Documents read from Excel keep the formatting of the cells, e.g., bold text and links.
+// The zero value, as well as a nil *Document, is an empty document without columns.
+type Document struct {
+	doc common.Document
+}
+
+// NewDocument returns a document with the given comments, e.g., from a manuscript management system.
+// If comments name a review round, the document has a section per round in the order the rounds
+// first appear; comments without a round belong to the first round. All rounds have the columns ID,
+// Reviewer (if any comment names one), Comment, Response, and the field names in the order they first appear.
+func NewDocument(comments []Comment) *Document {
+	cols := newColumns(comments)
+	var rounds []string
+	for _, c := range comments {
+		if c.Round != "" && !slices.Contains(rounds, c.Round) {
+			rounds = append(rounds, c.Round)
+		}
+	}
+	if len(rounds) == 0 {
+		return &Document{doc: common.NewDocument(cols.table(comments))}
+	}
+
+	wb := &reader.Workbook{}
+	for i, name := range rounds {
+		var cs []Comment
+		for _, c := range comments {
+			if c.Round == name || (c.Round == "" && i == 0) {
+				cs = append(cs, c)
+			}
+		}
+		wb.Sheets = append(wb.Sheets, reader.Sheet{Name: name, Data: cols.table(cs)})
+	}
+	return &Document{doc: common.NewRoundsDocument(wb)}
+}
+
+// columns are the headers and roles of a document built from comments.
+type columns struct {
+	headers      []string
+	roles        []reader.Role
+	withReviewer bool
+	fields       []string
+}
+
+// newColumns returns the columns that hold the given comments.
+func newColumns(comments []Comment) columns {
+	cols := columns{headers: []string{HeaderID}, roles: []reader.Role{reader.IDRole}}
+	if slices.ContainsFunc(comments, func(c Comment) bool { return c.Reviewer != "" }) {
+		cols.withReviewer = true
+		cols.headers = append(cols.headers, HeaderReviewer)
+		cols.roles = append(cols.roles, reader.ReviewerRole)
+	}
+	cols.headers = append(cols.headers, HeaderComment, HeaderResponse)
+	cols.roles = append(cols.roles, reader.CommentRole, reader.ResponseRole)
+	for _, c := range comments {
+		for _, f := range c.Fields {
+			if !slices.Contains(cols.fields, f.Name) {
+				cols.fields = append(cols.fields, f.Name)
+				cols.headers = append(cols.headers, f.Name)
+				cols.roles = append(cols.roles, reader.NoRole)
+			}
+		}
+	}
+	return cols
+}
+
+// table returns the tabular data of the comments with the roles of the columns assigned.
+func (cols columns) table(comments []Comment) *reader.TabularData {
+	td := &reader.TabularData{Headers: slices.Clone(cols.headers), Roles: slices.Clone(cols.roles)}
+	for _, c := range comments {
+		rec := []string{c.ID}
+		if cols.withReviewer {
+			rec = append(rec, c.Reviewer)
+		}
+		rec = append(rec, c.Comment, c.Response)
+		for _, name := range cols.fields {
+			var value string
+			if i := slices.IndexFunc(c.Fields, func(f Field) bool { return f.Name == name }); i >= 0 {
+				value = c.Fields[i].Value
+			}
+			rec = append(rec, value)
+		}
+		td.Records = append(td.Records, rec)
+		td.Rows = append(td.Rows, c.Row)
+	}
+	return td
+}
+
+// document returns the document for the templates; the zero value yields an empty table.
+func (d *Document) document() common.Document {
+	if d == nil || d.doc.Data == nil && d.doc.Rounds == nil {
+		return common.NewDocument(&reader.TabularData{})
+	}
+	return d.doc
+}
+
+// sheets returns the tables of the document; a document without rounds has a single unnamed table.
+func (d *Document) sheets() []reader.Sheet {
+	doc := d.document()
+	if doc.Rounds != nil {
+		return doc.Rounds.Sheets
+	}
+	return []reader.Sheet{{Data: doc.Data}}
+}
+
+// last returns the table of the last review round, or the only table.
+func (d *Document) last() *reader.TabularData {
+	sheets := d.sheets()
+	return sheets[len(sheets)-1].Data
+}
+
+// Headers returns the column headers of the document. With several review rounds,
+// these are the headers of the last round.
+func (d *Document) Headers() []string {
+	return slices.Clone(d.last().Headers)
+}
+
+// Rounds returns the names of the review rounds in chronological order,
+// or nil for a document without rounds.
+func (d *Document) Rounds() []string {
+	doc := d.document()
+	if doc.Rounds == nil {
+		return nil
+	}
+	var res []string
+	for _, s := range doc.Rounds.Sheets {
+		res = append(res, s.Name)
+	}
+	return res
+}
+
+// Comments returns the comments of all review rounds in chronological order.
+// The response is the column with the response role or, without one, the first column without a role.
+// All other columns except reviewer and hidden ones are fields. The reviewer is only set for
+// documents with a reviewer column; the ID scheme yields the reviewer of an empty reviewer cell.
+func (d *Document) Comments() []Comment {
+	var res []Comment
+	for _, s := range d.sheets() {
+		rj := model.New(s.Data, common.Options{})
+		for _, c := range rj.Comments() {
+			res = append(res, newComment(rj, c, s.Name))
+		}
+	}
+	return res
+}
+
+// RenderOrder returns the comments in the order in which LaTeX and Typst render them: the review rounds
+// in the order of the options, see Options.AppendixRounds, and within a round, the comments grouped
+// by the reviewers of the ID scheme of the options, e.g., to map the generated source back to the rows.
+func (d *Document) RenderOrder(opts Options) []Comment {
+	sheets := d.sheets()
+	order, _ := common.RoundOrder(len(sheets), opts.AppendixRounds)
+	var res []Comment
+	for _, i := range order {
+		rj := model.New(sheets[i].Data, opts.internal())
+		for _, rev := range rj.Reviewers {
+			for _, c := range rev.Comments {
+				res = append(res, newComment(rj, c, sheets[i].Name))
+			}
+		}
+	}
+	return res
+}
+
+// newComment converts a comment of the rejoinder of the given round.
+func newComment(rj model.Rejoinder, c model.Comment, round string) Comment {
+	res := Comment{
+		ID:       c.ID.Text,
+		Comment:  c.Comment.Text,
+		Response: c.Response.Text,
+		Round:    round,
+		Row:      c.Row,
+	}
+	if rj.Columns.HasReviewer {
+		res.Reviewer = c.Reviewer
+	}
+	for _, h := range rj.Columns.Details {
+		if cell, ok := c.Extra[h]; ok {
+			res.Fields = append(res.Fields, Field{Name: h, Value: cell.Text})
+		}
+	}
+	return res
+}
+
+// Warnings reports the problems of the document that do not prevent rendering, e.g., IDs that
+// do not match the ID scheme of the options or references to IDs that do not exist.
+// With several review rounds, the messages name the round.
+func (d *Document) Warnings(opts Options) []string {
+	return d.warnings(func(td *reader.TabularData) []string { return templates.Warnings(td, opts.internal()) })
+}
+
+// warnings collects the warnings of the sheets and names the sheet in the messages.
+func (d *Document) warnings(sheetWarnings func(td *reader.TabularData) []string) []string {
+	var res []string
+	for _, s := range d.sheets() {
+		for _, msg := range sheetWarnings(s.Data) {
+			if s.Name != "" {
+				msg = fmt.Sprintf("sheet %q: %s", s.Name, msg)
+			}
+			res = append(res, msg)
+		}
+	}
+	return res
+}
@@ -0,0 +1,392 @@
+package rejoinder
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andreas-bauer/rejoinderoo/internal/compile"
+)
+
+func TestNewDocument_Comments(t *testing.T) {
+	tests := []struct {
+		name     string
+		comments []Comment
+		headers  []string
+		rounds   []string
+	}{
+		{
+			name: "single round",
+			comments: []Comment{
+				{ID: "R1.1", Comment: "c1", Response: "r1", Fields: []Field{{"Action", "a1"}}, Row: 2},
+				{ID: "R1.2", Comment: "c2", Response: "r2", Fields: []Field{{"Action", ""}}, Row: 3},
+			},
+			headers: []string{"ID", "Comment", "Response", "Action"},
+		},
+		{
+			name: "reviewer column",
+			comments: []Comment{
+				{ID: "1", Reviewer: "Editor", Comment: "c1", Response: "r1"},
+				{ID: "2", Reviewer: "R1", Comment: "c2", Response: "r2"},
+			},
+			headers: []string{"ID", "Reviewer", "Comment", "Response"},
+		},
+		{
+			name: "rounds share the columns",
+			comments: []Comment{
+				{ID: "R1.1", Comment: "c1", Response: "r1", Fields: []Field{{"Action", "a1"}, {"Location", ""}}, Round: "Round 1"},
+				{ID: "R1.1", Comment: "c2", Response: "r2", Fields: []Field{{"Action", ""}, {"Location", "Sec. 2"}}, Round: "Round 2"},
+			},
+			headers: []string{"ID", "Comment", "Response", "Action", "Location"},
+			rounds:  []string{"Round 1", "Round 2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := NewDocument(tt.comments)
+			if got := doc.Headers(); !reflect.DeepEqual(got, tt.headers) {
+				t.Errorf("Headers() = %q, want %q", got, tt.headers)
+			}
+			if got := doc.Rounds(); !reflect.DeepEqual(got, tt.rounds) {
+				t.Errorf("Rounds() = %q, want %q", got, tt.rounds)
+			}
+			if got := doc.Comments(); !reflect.DeepEqual(got, tt.comments) {
+				t.Errorf("Comments() = %+v, want %+v", got, tt.comments)
+			}
+		})
+	}
+}
+
+func TestNewDocument_CommentsWithoutRound(t *testing.T) {
+	doc := NewDocument([]Comment{
+		{ID: "R1.1", Round: "Round 2"},
+		{ID: "R1.2"},
+	})
+	if got, want := doc.Rounds(), []string{"Round 2"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Rounds() = %q, want %q", got, want)
+	}
+	for _, c := range doc.Comments() {
+		if c.Round != "Round 2" {
+			t.Errorf("comment %s belongs to round %q, want the first round", c.ID, c.Round)
+		}
+	}
+}
+
+func TestDocument_Comments_Roles(t *testing.T) {
+	input := "No,Status,Remark,Answer,Where\n1,done,c1,r1,Sec. 1\n"
+	doc, err := Read(strings.NewReader(input), "responses.csv", ReadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = doc.Select(Selection{Roles: map[string]Role{"Status": HiddenRole, "Where": LocationRole}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Comment{{
+		ID:       "1",
+		Comment:  "c1",
+		Response: "r1",
+		Fields:   []Field{{"Where", "Sec. 1"}},
+		Row:      2,
+	}}
+	if got := doc.Comments(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Comments() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDocument_Comments_Reviewer(t *testing.T) {
+	input := "ID,Reviewer,Comment,Response\n1,Editor,c1,r1\nR2.1,,c2,r2\n"
+	doc, err := Read(strings.NewReader(input), "responses.csv", ReadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = doc.Select(Selection{Roles: map[string]Role{"Reviewer": ReviewerRole}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the ID scheme yields the reviewer of an empty reviewer cell
+	want := []Comment{
+		{ID: "1", Reviewer: "Editor", Comment: "c1", Response: "r1", Row: 2},
+		{ID: "R2.1", Reviewer: "R2", Comment: "c2", Response: "r2", Row: 3},
+	}
+	if got := doc.Comments(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Comments() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDocument_Empty(t *testing.T) {
+	doc, err := Read(strings.NewReader(""), "responses.csv", ReadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := doc.Comments(); len(got) != 0 {
+		t.Errorf("Comments() = %+v, want none", got)
+	}
+	for _, tmpl := range Templates() {
+		if got := tmpl.Warnings(doc, Options{}); len(got) != 0 {
+			t.Errorf("%s: Warnings() = %q, want none", tmpl.Name(), got)
+		}
+		if _, err := tmpl.RenderBytes(context.Background(), doc, Options{}); err != nil {
+			t.Errorf("%s: RenderBytes() error = %v", tmpl.Name(), err)
+		}
+	}
+}
+
+func TestDocument_ZeroValue(t *testing.T) {
+	for _, doc := range []*Document{nil, {}} {
+		if got := doc.Headers(); len(got) != 0 {
+			t.Errorf("Headers() = %q, want none", got)
+		}
+		if got := doc.Rounds(); got != nil {
+			t.Errorf("Rounds() = %q, want nil", got)
+		}
+		if got := doc.Comments(); len(got) != 0 {
+			t.Errorf("Comments() = %+v, want none", got)
+		}
+		if err := doc.Select(Selection{}); err != nil {
+			t.Errorf("Select() error = %v", err)
+		}
+		for _, tmpl := range Templates() {
+			if got := tmpl.Warnings(doc, Options{}); len(got) != 0 {
+				t.Errorf("%s: Warnings() = %q, want none", tmpl.Name(), got)
+			}
+			if _, err := tmpl.RenderBytes(context.Background(), doc, Options{}); err != nil {
+				t.Errorf("%s: RenderBytes() error = %v", tmpl.Name(), err)
+			}
+		}
+	}
+}
+
+func TestDocument_RenderOrder(t *testing.T) {
+	input := "ID,Comment,Response\nRev1.1,c1,r1\nRev2.1,c2,r2\nRev1.2,c3,r3\n"
+	doc, err := Read(strings.NewReader(input), "responses.csv", ReadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, c := range doc.RenderOrder(Options{}) {
+		ids = append(ids, c.ID)
+	}
+	if want := []string{"Rev1.1", "Rev1.2", "Rev2.1"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("RenderOrder() IDs = %q, want %q", ids, want)
+	}
+
+	// the source map maps the second response box to row 4, where Rev1.2 is
+	for _, name := range []string{"latex", "typst"} {
+		tmpl, err := LookupTemplate(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := tmpl.RenderBytes(context.Background(), doc, Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var origins []compile.Origin
+		for _, c := range doc.RenderOrder(Options{}) {
+			origins = append(origins, compile.Origin{Row: c.Row, ID: c.ID})
+		}
+		sm := compile.NewSourceMap(out, tmpl.Extension(), origins)
+
+		line := bytes.Count(out[:bytes.Index(out, []byte("c3"))], []byte("\n")) + 1
+		if got, ok := sm.Lookup(line); !ok || *got != (compile.Origin{Row: 4, ID: "Rev1.2"}) {
+			t.Errorf("%s: Lookup(%d) = %+v, want row 4 of Rev1.2", name, line, got)
+		}
+	}
+}
+
+func TestDocument_Select(t *testing.T) {
+	input := "ID,Comment,Response,Status,Notes\nR2.1,c1,r1,done,n1\nR1.1,c2,r2,open,n2\nR1.2,c3,r3,done,n3\n"
+
+	tests := []struct {
+		name    string
+		sel     Selection
+		headers []string
+		ids     []string
+		wantErr string
+	}{
+		{
+			name:    "zero value keeps the document",
+			headers: []string{"ID", "Comment", "Response", "Status", "Notes"},
+			ids:     []string{"R2.1", "R1.1", "R1.2"},
+		},
+		{
+			name:    "columns with a role are kept",
+			sel:     Selection{Columns: []string{"ID", "Comment", "Response"}, Roles: map[string]Role{"Status": HiddenRole}},
+			headers: []string{"ID", "Comment", "Response", "Status"},
+			ids:     []string{"R2.1", "R1.1", "R1.2"},
+		},
+		{
+			name:    "filter and order",
+			sel:     Selection{Where: "Status=done", Order: "id"},
+			headers: []string{"ID", "Comment", "Response", "Status", "Notes"},
+			ids:     []string{"R1.2", "R2.1"},
+		},
+		{
+			name:    "missing column",
+			sel:     Selection{Columns: []string{"ID", "Comment", "Answer"}},
+			wantErr: `column(s) ["Answer"] not found`,
+		},
+		{
+			name:    "invalid filter",
+			sel:     Selection{Where: "Status"},
+			wantErr: "Status",
+		},
+		{
+			name:    "unknown filter column",
+			sel:     Selection{Where: "Owner=Andreas", Order: "id"},
+			wantErr: `column "Owner" not found`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Read(strings.NewReader(input), "responses.csv", ReadOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = doc.Select(tt.sel)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Select() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				if got := len(doc.Comments()); got != 3 {
+					t.Errorf("failed Select() changed the document, got %d comments", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Select() error = %v", err)
+			}
+			if got := doc.Headers(); !reflect.DeepEqual(got, tt.headers) {
+				t.Errorf("Headers() = %q, want %q", got, tt.headers)
+			}
+			var ids []string
+			for _, c := range doc.Comments() {
+				ids = append(ids, c.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.ids) {
+				t.Errorf("IDs = %q, want %q", ids, tt.ids)
+			}
+		})
+	}
+}
+
+func TestRead_Rounds(t *testing.T) {
+	input := "ID,Comment,Response\nR1.1,c1,r1\n"
+	tests := []struct {
+		name    string
+		opts    ReadOptions
+		wantErr string
+	}{
+		{"rounds of a CSV file", ReadOptions{AllRounds: true}, "review rounds require a file with several sheets"},
+		{"sheet and rounds", ReadOptions{Sheet: "1", Rounds: []string{"1"}}, "cannot be combined"},
+		{"unsupported format", ReadOptions{}, "not supported"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := "responses.csv"
+			if tt.name == "unsupported format" {
+				filename = "responses.pdf"
+			}
+			_, err := Read(strings.NewReader(input), filename, tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Read() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDocument_Warnings(t *testing.T) {
+	doc := NewDocument([]Comment{
+		{ID: "R1.1", Response: "see R1.9", Round: "Round 1", Row: 2},
+		{ID: "R1.1", Response: "done", Round: "Round 2", Row: 2},
+	})
+	want := []string{`sheet "Round 1": row 2 refers to ID "R1.9", which does not exist`}
+	if got := doc.Warnings(Options{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Warnings() = %q, want %q", got, want)
+	}
+}
+
+func TestTemplate_Warnings(t *testing.T) {
+	doc := NewDocument([]Comment{{ID: "R1.1", Comment: "Nice 🎉", Response: "see R1.9", Row: 2}})
+	latex, err := LookupTemplate("latex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`row 2 refers to ID "R1.9", which does not exist`,
+		`row 2 contains characters "🎉" that LaTeX cannot typeset, they are replaced by "?"`,
+	}
+	if got := latex.Warnings(doc, Options{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Warnings() = %q, want %q", got, want)
+	}
+
+	markdown, err := LookupTemplate("markdown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := markdown.Warnings(doc, Options{}); !reflect.DeepEqual(got, want[:1]) {
+		t.Errorf("Warnings() = %q, want %q", got, want[:1])
+	}
+}
+
+func TestOptions_Internal(t *testing.T) {
+	scheme, err := ParseIDScheme("verbose")
+	if err != nil {
+		t.Fatal(err)
+	}
+	palette, err := ParsePalette("pastel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := Options{
+		Meta:             Metadata{Title: "Paper", Authors: []string{"A"}},
+		AppendixRounds:   true,
+		CellMarkup:       true,
+		Escaping:         MathEscaping,
+		UnicodeFallbacks: map[rune]string{'✓': "ok"},
+		IDScheme:         scheme,
+		Palette:          palette,
+		Colors:           map[string]Color{"R1": "#FF0000"},
+		TableOfContents:  true,
+		Template:         &CustomTemplate{Name: "house.tmpl", Text: "{{ .Meta.Title }}"},
+	}
+
+	got := opts.internal()
+	if got.Meta.Title != "Paper" || !got.AppendixRounds || !got.CellMarkup || got.Escaping.String() != "math" ||
+		got.UnicodeFallbacks['✓'] != "ok" || got.IDScheme.String() != "verbose" || got.Palette.Name != palette.Name() ||
+		got.Colors["R1"] != "#FF0000" || !got.TableOfContents || got.Template.Name != "house.tmpl" {
+		t.Errorf("internal() = %+v, want the options %+v", got, opts)
+	}
+	if got := (Options{}).internal(); got.IDScheme != nil || got.Template != nil || got.Colors != nil {
+		t.Errorf("internal() of the zero value = %+v, want the defaults", got)
+	}
+}
+
+func TestRoleNames(t *testing.T) {
+	for _, name := range RoleNames() {
+		r, err := ParseRole(name)
+		if err != nil || r.String() != name {
+			t.Errorf("ParseRole(%q) = %v, %v", name, r, err)
+		}
+	}
+	roles, err := ParseRoles("No=id, Status=hidden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]Role{"No": IDRole, "Status": HiddenRole}; !reflect.DeepEqual(roles, want) {
+		t.Errorf("ParseRoles() = %v, want %v", roles, want)
+	}
+}
+
+func TestOptionNames(t *testing.T) {
+	names := []string{OptionPalette, OptionMarkup, OptionEscaping, OptionTOC, OptionRounds, OptionCustom, OptionPDF}
+	if known := OptionNames(); !reflect.DeepEqual(names, known) {
+		t.Errorf("option constants = %q, want %q", names, known)
+	}
+}
@@ -0,0 +1,93 @@
+package rejoinder_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/andreas-bauer/rejoinderoo/rejoinder"
+)
+
+// summary is a custom Markdown template that lists the comment IDs of each reviewer.
+const summary = `{{ range .Reviewers }}{{ .ReviewerID }}:{{ range .Responses }} {{ .ID }}{{ end }}
+{{ end }}`
+
+func ExampleNewDocument() {
+	doc := rejoinder.NewDocument([]rejoinder.Comment{
+		{ID: "R1.1", Comment: "The threats to validity are missing.", Response: "We added them in Section 6."},
+		{ID: "R1.2", Comment: "Typo in Section 2.", Response: "Fixed."},
+		{ID: "R2.1", Comment: "The related work is incomplete.", Response: "We added three studies.",
+			Fields: []rejoinder.Field{{Name: "Action", Value: "Extended Section 2."}}},
+	})
+
+	tmpl, err := rejoinder.LookupTemplate("markdown")
+	if err != nil {
+		log.Fatal(err)
+	}
+	custom, err := rejoinder.NewCustomTemplate("summary.tmpl", []byte(summary))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := tmpl.Render(context.Background(), os.Stdout, doc, rejoinder.Options{Template: custom}); err != nil {
+		log.Fatal(err)
+	}
+	// Output:
+	// R1: R1.1 R1.2
+	// R2: R2.1
+}
+
+func ExampleRead() {
+	input := `ID,Comment,Response,Status
+R2.1,The related work is incomplete.,We added three studies.,done
+R1.2,Typo in Section 2.,Fixed.,done
+R1.1,The threats to validity are missing.,We will add them.,open
+`
+	doc, err := rejoinder.Read(strings.NewReader(input), "responses.csv", rejoinder.ReadOptions{})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// hide the status, keep the done comments, and sort them by ID
+	err = doc.Select(rejoinder.Selection{
+		Roles: map[string]rejoinder.Role{"Status": rejoinder.HiddenRole},
+		Where: "Status=done",
+		Order: "id",
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, c := range doc.Comments() {
+		fmt.Printf("%s (row %d): %s\n", c.ID, c.Row, c.Response)
+	}
+	// Output:
+	// R1.2 (row 3): Fixed.
+	// R2.1 (row 2): We added three studies.
+}
+
+func ExampleLookupTemplate() {
+	tmpl, err := rejoinder.LookupTemplate("typ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(tmpl.Name(), tmpl.Extension(), tmpl.Supports(rejoinder.OptionTOC))
+
+	_, err = rejoinder.LookupTemplate("pdf")
+	fmt.Println(err)
+	// Output:
+	// Typst .typ true
+	// template "pdf" is not available, choose one of [LaTeX Typst Markdown DOCX HTML]
+}
+
+func ExampleTemplates() {
+	for _, t := range rejoinder.Templates() {
+		fmt.Printf("%s (%s)\n", t.Name(), t.Extension())
+	}
+	// Output:
+	// LaTeX (.tex)
+	// Typst (.typ)
+	// Markdown (.md)
+	// DOCX (.docx)
+	// HTML (.html)
+}
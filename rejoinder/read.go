@@ -0,0 +1,90 @@
+package rejoinder
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/andreas-bauer/rejoinderoo/internal/reader"
+	"github.com/andreas-bauer/rejoinderoo/internal/templates/common"
+)
+
+// ReadOptions selects the sheets to read from a workbook. The zero value reads the first sheet.
+type ReadOptions struct {
+	// Sheet selects the sheet by name or 1-based index. Empty selects the first sheet.
+	// Formats without sheets, e.g., CSV, ignore it.
+	Sheet string
+	// Rounds selects several sheets by name or 1-based index, which are read as review rounds
+	// in the given order. Empty selects a single sheet.
+	Rounds []string
+	// AllRounds reads all sheets as review rounds in the order of the workbook.
+	AllRounds bool
+}
+
+// SupportedFileExtensions returns the file extensions of the formats that can be read, e.g., ".xlsx".
+func SupportedFileExtensions() []string {
+	return reader.SupportedFileExtensions()
+}
+
+// ReadFile reads the comments from the spreadsheet at the given path, see Read.
+func ReadFile(path string, opts ReadOptions) (*Document, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Read(file, path, opts)
+}
+
+// Read reads the comments from a spreadsheet. The extension of the file name selects the format,
+// see SupportedFileExtensions. Each row below the header row holds a comment; see Select
+// for the roles of the columns.
+func Read(r io.Reader, filename string, opts ReadOptions) (*Document, error) {
+	tr, err := reader.NewReader(filename)
+	if err != nil {
+		return nil, err
+	}
+	sr, ok := tr.(reader.SheetReader)
+	if !opts.AllRounds && len(opts.Rounds) == 0 {
+		if ok && opts.Sheet != "" {
+			sr.SelectSheet(opts.Sheet)
+		}
+		td, err := tr.Read(r)
+		if err != nil {
+			return nil, err
+		}
+		return &Document{doc: common.NewDocument(td)}, nil
+	}
+
+	if opts.Sheet != "" {
+		return nil, fmt.Errorf("a sheet cannot be combined with review rounds")
+	}
+	if !ok {
+		return nil, fmt.Errorf("review rounds require a file with several sheets, e.g., an Excel workbook")
+	}
+	var sheets []string
+	if !opts.AllRounds {
+		sheets = opts.Rounds
+	}
+	wb, err := sr.ReadSheets(r, sheets)
+	if err != nil {
+		return nil, err
+	}
+	if len(wb.Sheets) == 0 {
+		return nil, fmt.Errorf("workbook does not contain any sheets")
+	}
+	return &Document{doc: common.NewRoundsDocument(wb)}, nil
+}
+
+// Sheets returns the names of the sheets of a workbook, or nil for formats without sheets, e.g., CSV.
+func Sheets(r io.Reader, filename string) ([]string, error) {
+	tr, err := reader.NewReader(filename)
+	if err != nil {
+		return nil, err
+	}
+	sr, ok := tr.(reader.SheetReader)
+	if !ok {
+		return nil, nil
+	}
+	return sr.Sheets(r)
+}
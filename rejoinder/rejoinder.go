@@ -0,0 +1,279 @@
+// Package rejoinder generates rejoinders, i.e., the authors' responses to the comments
+// of the reviewers of a scientific paper, e.g., as LaTeX, Typst, Markdown, DOCX, or HTML.
+//
+// A Document holds the comments of one or several review rounds. It is read from a
+// spreadsheet with Read or ReadFile, or built from typed comments with NewDocument, e.g.,
+// by a manuscript management system. Select assigns column roles, filters, and orders the
+// comments, and a Template renders the document:
+//
+//	doc, err := rejoinder.ReadFile("responses.xlsx", rejoinder.ReadOptions{})
+//	if err != nil { ... }
+//	tmpl, err := rejoinder.LookupTemplate("typst")
+//	if err != nil { ... }
+//	err = tmpl.Render(ctx, w, doc, rejoinder.Options{Meta: rejoinder.Metadata{Title: "..."}})
+//
+// The command line tool and the web server of rejoinderoo are clients of this package.
+//
+// The package follows semantic versioning, see Version: within a major version,
+// exported identifiers are neither removed nor changed incompatibly.
+package rejoinder
+
+import (
+	"cmp"
+
+	"github.com/andreas-bauer/rejoinderoo/internal/reader"
+	"github.com/andreas-bauer/rejoinderoo/internal/templates/common"
+)
+
+// Version is the semantic version of the package API.
+const Version = "1.0.0"
+
+// Options configures how a template renders the rejoinder. The zero value renders
+// with the defaults, e.g., the default palette and ID scheme.
+type Options struct {
+	Meta Metadata
+	// AppendixRounds moves all but the last review round into an appendix,
+	// if several rounds are rendered into one document.
+	AppendixRounds bool
+	// CellMarkup converts Markdown-like markup in cells, e.g., lists, **bold**, and [links](url),
+	// to the markup of the template instead of escaping it.
+	CellMarkup bool
+	// Escaping selects whether inline math and raw blocks in cells are passed through.
+	Escaping Escaping
+	// UnicodeFallbacks replaces characters that the template cannot represent, e.g., emoji in LaTeX.
+	// Templates without such limits ignore it.
+	UnicodeFallbacks map[rune]string
+	// IDScheme extracts the reviewer from comment IDs. Nil selects the default scheme.
+	IDScheme *IDScheme
+	// Palette colors the responses of each reviewer. The zero value selects the default palette.
+	Palette Palette
+	// Colors overrides the palette color of individual reviewers, keyed by reviewer ID.
+	Colors map[string]Color
+	// TableOfContents adds a table of contents of the reviewer sections.
+	TableOfContents bool
+	// Template replaces the built-in template of the format. Nil selects the built-in template.
+	Template *CustomTemplate
+}
+
+// internal converts the options for the templates.
+func (o Options) internal() common.Options {
+	var colors map[string]common.Color
+	if o.Colors != nil {
+		colors = make(map[string]common.Color, len(o.Colors))
+		for r, c := range o.Colors {
+			colors[r] = common.Color(c)
+		}
+	}
+	return common.Options{
+		Meta:             common.Metadata(o.Meta),
+		AppendixRounds:   o.AppendixRounds,
+		CellMarkup:       o.CellMarkup,
+		Escaping:         common.Escaping(o.Escaping),
+		UnicodeFallbacks: o.UnicodeFallbacks,
+		IDScheme:         o.IDScheme.internal(),
+		Palette:          o.Palette.palette,
+		Colors:           colors,
+		TableOfContents:  o.TableOfContents,
+		Template:         o.Template.internal(),
+	}
+}
+
+// Metadata holds information about the paper that is printed in the rejoinder.
+// Empty fields are replaced by placeholders in the generated document.
+type Metadata struct {
+	Title        string
+	ManuscriptID string
+	Authors      []string
+	Editor       string
+	Venue        string
+	CoverLetter  string
+	KeyChanges   []string
+}
+
+// IDScheme extracts the reviewer and the comment number from comment IDs, e.g., "R1.2".
+type IDScheme struct {
+	scheme *common.IDScheme
+}
+
+// String returns the name of the preset or the regular expression of the scheme.
+func (s *IDScheme) String() string {
+	return s.internal().String()
+}
+
+// internal returns the scheme for the templates; nil selects the default scheme.
+func (s *IDScheme) internal() *common.IDScheme {
+	if s == nil {
+		return nil
+	}
+	return s.scheme
+}
+
+// Palette colors the responses of each reviewer. The zero value is the default palette.
+type Palette struct {
+	palette common.Palette
+}
+
+// Name returns the name of the palette, see PaletteNames.
+func (p Palette) Name() string {
+	return cmp.Or(p.palette.Name, PaletteNames()[0])
+}
+
+// Color is an RGB color in hexadecimal notation, e.g., "#E69F00".
+type Color string
+
+// Escaping selects whether inline math and raw blocks in cells are passed through.
+type Escaping int
+
+// Escapings of the special characters of LaTeX and Typst.
+const (
+	// StrictEscaping escapes all special characters.
+	StrictEscaping = Escaping(common.StrictEscaping)
+	// MathEscaping passes inline math, raw blocks, and, for LaTeX, commands through.
+	MathEscaping = Escaping(common.MathEscaping)
+)
+
+// String returns the name of the escaping, see EscapingNames.
+func (e Escaping) String() string {
+	return common.Escaping(e).String()
+}
+
+// CustomTemplate replaces the built-in template of a format, see Template.ValidateCustom.
+type CustomTemplate struct {
+	// Name identifies the template in error messages, e.g., the file name.
+	Name string
+	// Text is the source of the template.
+	Text string
+}
+
+// internal returns the custom template for the templates; nil selects the built-in template.
+func (c *CustomTemplate) internal() *common.CustomTemplate {
+	if c == nil {
+		return nil
+	}
+	return &common.CustomTemplate{Name: c.Name, Text: c.Text}
+}
+
+// Role tells the templates how to render a column, see Selection.Roles.
+type Role int
+
+// Roles of the columns.
+const (
+	NoRole       = Role(reader.NoRole)
+	IDRole       = Role(reader.IDRole)
+	ReviewerRole = Role(reader.ReviewerRole)
+	CommentRole  = Role(reader.CommentRole)
+	ResponseRole = Role(reader.ResponseRole)
+	ActionRole   = Role(reader.ActionRole)
+	LocationRole = Role(reader.LocationRole)
+	HiddenRole   = Role(reader.HiddenRole)
+)
+
+// String returns the name of the role, see RoleNames.
+func (r Role) String() string {
+	return reader.Role(r).String()
+}
+
+// roles converts the roles of the columns for the reader.
+func roles(rs map[string]Role) map[string]reader.Role {
+	if rs == nil {
+		return nil
+	}
+	res := make(map[string]reader.Role, len(rs))
+	for h, r := range rs {
+		res[h] = reader.Role(r)
+	}
+	return res
+}
+
+// ParseIDScheme returns the preset ID scheme with the given name, see IDSchemePresets,
+// or a scheme with the given regular expression. An empty scheme selects the default scheme.
+func ParseIDScheme(scheme string) (*IDScheme, error) {
+	s, err := common.ParseIDScheme(scheme)
+	if err != nil {
+		return nil, err
+	}
+	return &IDScheme{scheme: s}, nil
+}
+
+// IDSchemePresets returns the names of the preset ID schemes.
+func IDSchemePresets() []string {
+	return common.IDSchemePresets()
+}
+
+// IDSchemeExample returns an example ID of the preset ID scheme, or an empty string.
+func IDSchemeExample(name string) string {
+	return common.IDSchemeExample(name)
+}
+
+// ParsePalette returns the palette with the given name, see PaletteNames.
+// An empty name selects the default palette.
+func ParsePalette(name string) (Palette, error) {
+	p, err := common.ParsePalette(name)
+	return Palette{palette: p}, err
+}
+
+// PaletteNames returns the names of the palettes; the first one is the default.
+func PaletteNames() []string {
+	return common.PaletteNames()
+}
+
+// ParseColor parses a color in the notation #RRGGBB or #RGB. The leading # is optional.
+func ParseColor(s string) (Color, error) {
+	c, err := common.ParseColor(s)
+	return Color(c), err
+}
+
+// ParseEscaping returns the escaping with the given name, see EscapingNames.
+// An empty name selects strict escaping.
+func ParseEscaping(name string) (Escaping, error) {
+	e, err := common.ParseEscaping(name)
+	return Escaping(e), err
+}
+
+// EscapingNames returns the names of the escapings.
+func EscapingNames() []string {
+	return common.EscapingNames()
+}
+
+// ParseRole returns the role with the given name, see RoleNames. An empty name selects no role.
+func ParseRole(name string) (Role, error) {
+	r, err := reader.ParseRole(name)
+	return Role(r), err
+}
+
+// ParseRoles parses a comma-separated list of column roles of the form "<column>=<role>",
+// e.g., "No=id, Remark=comment, Status=hidden".
+func ParseRoles(spec string) (map[string]Role, error) {
+	rs, err := reader.ParseRoles(spec)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string]Role, len(rs))
+	for h, r := range rs {
+		res[h] = Role(r)
+	}
+	return res, nil
+}
+
+// RoleNames returns the names of the roles that can be assigned to columns.
+func RoleNames() []string {
+	return reader.RoleNames()
+}
+
+// NewCustomTemplate returns a custom template with the given name and source.
+func NewCustomTemplate(name string, text []byte) (*CustomTemplate, error) {
+	return customTemplate(common.NewCustomTemplate(name, text))
+}
+
+// LoadCustomTemplate reads a custom template from the file at the given path.
+func LoadCustomTemplate(path string) (*CustomTemplate, error) {
+	return customTemplate(common.LoadCustomTemplate(path))
+}
+
+// customTemplate converts a custom template of the templates.
+func customTemplate(c *common.CustomTemplate, err error) (*CustomTemplate, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &CustomTemplate{Name: c.Name, Text: c.Text}, nil
+}
@@ -0,0 +1,119 @@
+package rejoinder
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/andreas-bauer/rejoinderoo/internal/filter"
+	"github.com/andreas-bauer/rejoinderoo/internal/order"
+	"github.com/andreas-bauer/rejoinderoo/internal/reader"
+)
+
+// Selection selects the columns and comments of a document and their order.
+// The zero value keeps the document as it is.
+type Selection struct {
+	// Columns are the headers of the columns to render, in the given order, except for the
+	// column with the ID role, which comes first. Columns with a role are kept even if they
+	// are not listed. Empty keeps all columns.
+	Columns []string
+	// Roles assigns roles to the columns by header, e.g., {"No": IDRole, "Status": HiddenRole}.
+	// Other columns have no role. Nil keeps the roles of the document; documents read from
+	// a spreadsheet have none, so that the first column holds the IDs and the second the comments.
+	Roles map[string]Role
+	// Where keeps only the comments that match a filter, e.g., "Status!=done" or
+	// "Responsible in (Andreas, Maria)". Conditions are joined with and.
+	Where string
+	// Order sorts the comments, one of OrderModes, e.g., "id", "column=Status",
+	// or "reviewers=Editor, R2, R1". Empty keeps the order of the input.
+	Order string
+	// IDScheme parses the IDs for ordering by reviewer. It should be the ID scheme of the options
+	// used for rendering. Nil selects the default scheme.
+	IDScheme *IDScheme
+}
+
+// OrderModes returns the modes of Selection.Order.
+func OrderModes() []string {
+	return order.Modes()
+}
+
+// Validate reports syntax errors of the filter and the order, e.g., before any input is read.
+// Unknown columns are reported by Select.
+func (s Selection) Validate() error {
+	if _, err := filter.Parse(s.Where); err != nil {
+		return err
+	}
+	_, err := order.Parse(s.Order)
+	return err
+}
+
+// Select assigns the roles of the columns, keeps the comments that match the filter in the
+// given order, and keeps the selected columns, in all review rounds. With several review rounds,
+// every round must provide the selected columns. The document is unchanged if Select fails.
+func (d *Document) Select(sel Selection) error {
+	where, err := filter.Parse(sel.Where)
+	if err != nil {
+		return err
+	}
+	ord, err := order.Parse(sel.Order)
+	if err != nil {
+		return err
+	}
+
+	columns := slices.Clone(sel.Columns)
+	if len(columns) == 0 {
+		columns = d.Headers()
+	}
+	for _, h := range d.last().Headers {
+		if sel.Roles[h] != NoRole && !slices.Contains(columns, h) {
+			columns = append(columns, h)
+		}
+	}
+
+	sheets := d.sheets()
+	selected := make([]*reader.TabularData, len(sheets))
+	for i, s := range sheets {
+		td, err := selectTable(clone(s.Data), columns, sel, where, ord)
+		if err != nil {
+			if s.Name != "" {
+				return fmt.Errorf("sheet %q: %w", s.Name, err)
+			}
+			return err
+		}
+		selected[i] = td
+	}
+	for i := range sheets {
+		*sheets[i].Data = *selected[i]
+	}
+	return nil
+}
+
+// selectTable applies the selection to the tabular data, see Document.Select.
+func selectTable(td *reader.TabularData, columns []string, sel Selection, where filter.Filter, ord order.Order) (*reader.TabularData, error) {
+	if missing := td.MissingHeaders(columns); len(missing) > 0 {
+		return nil, fmt.Errorf("column(s) %q not found, available columns are %q", missing, td.Headers)
+	}
+	if sel.Roles != nil {
+		if err := td.SetRoles(roles(sel.Roles)); err != nil {
+			return nil, err
+		}
+	}
+	if err := where.Apply(td); err != nil {
+		return nil, err
+	}
+	if err := ord.Apply(td, td.IDHeader(columns), sel.IDScheme.internal()); err != nil {
+		return nil, err
+	}
+	td.Keep(columns)
+	return td, nil
+}
+
+// clone returns a copy of the tabular data that can be changed without changing the original.
+func clone(td *reader.TabularData) *reader.TabularData {
+	return &reader.TabularData{
+		Headers: slices.Clone(td.Headers),
+		Records: slices.Clone(td.Records),
+		Rows:    slices.Clone(td.Rows),
+		Rich:    slices.Clone(td.Rich),
+		Roles:   slices.Clone(td.Roles),
+	}
+}
@@ -0,0 +1,172 @@
+package rejoinder
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/andreas-bauer/rejoinderoo/internal/reader"
+	"github.com/andreas-bauer/rejoinderoo/internal/templates"
+)
+
+// Template is an output format of the rejoinder, e.g., LaTeX or DOCX.
+type Template struct {
+	format templates.Format
+}
+
+// Option names of the settings that templates take into account, see Template.Supports.
+// The names are those of the command line flags.
+const (
+	OptionPalette  = "palette"
+	OptionMarkup   = "markup"
+	OptionEscaping = "escaping"
+	OptionTOC      = "toc"
+	OptionRounds   = "rounds"
+	OptionCustom   = "template-file"
+	OptionPDF      = "pdf"
+)
+
+// Templates returns the available templates in the order they are offered. The first one is the default.
+func Templates() []Template {
+	var res []Template
+	for _, f := range templates.Formats() {
+		res = append(res, Template{format: f})
+	}
+	return res
+}
+
+// TemplateNames returns the names of the available templates, see Templates.
+func TemplateNames() []string {
+	var res []string
+	for _, t := range Templates() {
+		res = append(res, t.Name())
+	}
+	return res
+}
+
+// LookupTemplate returns the template with the given name or alias, e.g., "LaTeX" or "tex".
+// The comparison is case-insensitive. An empty name selects the default template.
+func LookupTemplate(name string) (Template, error) {
+	f, err := templates.Lookup(name)
+	if err != nil {
+		return Template{}, err
+	}
+	return Template{format: f}, nil
+}
+
+// Name returns the display name of the template, e.g., "LaTeX".
+func (t Template) Name() string {
+	return t.format.Name
+}
+
+// Aliases returns further names that select the template, e.g., "tex".
+func (t Template) Aliases() []string {
+	return append([]string(nil), t.format.Aliases...)
+}
+
+// Description summarizes the output, e.g., for help texts and forms.
+func (t Template) Description() string {
+	return t.format.Description
+}
+
+// Extension returns the file extension of the output, e.g., ".tex".
+func (t Template) Extension() string {
+	return t.format.Extension
+}
+
+// MIMEType returns the media type of the output, e.g., for downloads.
+func (t Template) MIMEType() string {
+	return t.format.MIMEType
+}
+
+// Binary reports whether the output is binary data, e.g., a DOCX package, rather than text.
+func (t Template) Binary() bool {
+	return t.format.Binary
+}
+
+// Options returns the names of the settings that the template takes into account, see Supports.
+func (t Template) Options() []string {
+	res := make([]string, len(t.format.Options))
+	for i, o := range t.format.Options {
+		res[i] = o.Name
+	}
+	return res
+}
+
+// Supports reports whether the template takes the option with the given name into account,
+// e.g., OptionTOC. Templates without OptionRounds cannot render documents with several review rounds.
+func (t Template) Supports(option string) bool {
+	for _, o := range t.format.Options {
+		if o.Name == option {
+			return true
+		}
+	}
+	return false
+}
+
+// Builtin returns the source of the built-in template, e.g., as a starting point for a custom template.
+// It reports false for templates that do not support custom templates.
+func (t Template) Builtin() (string, bool) {
+	if t.format.New == nil {
+		return "", false
+	}
+	tmpl, ok := t.format.New().(templates.CustomizableTemplate)
+	if !ok {
+		return "", false
+	}
+	return tmpl.Builtin(), true
+}
+
+// ValidateCustom renders sample data with the custom template in place of the built-in one
+// to report parse and execute errors, e.g., unknown fields, before any input is processed.
+// It stops with the error of the context once the context is done.
+func (t Template) ValidateCustom(ctx context.Context, custom *CustomTemplate) error {
+	return templates.ValidateCustom(ctx, t.format, custom.internal())
+}
+
+// Render writes the rejoinder of the document to w. It stops with the error of the context
+// once the context is done, e.g., if the client of a web server disconnects.
+func (t Template) Render(ctx context.Context, w io.Writer, doc *Document, opts Options) error {
+	if t.format.New == nil {
+		return fmt.Errorf("template is not initialized, see LookupTemplate")
+	}
+	return t.format.New().Render(ctx, w, doc.document(), opts.internal())
+}
+
+// RenderBytes renders the rejoinder of the document into memory, see Render.
+func (t Template) RenderBytes(ctx context.Context, doc *Document, opts Options) ([]byte, error) {
+	if t.format.New == nil {
+		return nil, fmt.Errorf("template is not initialized, see LookupTemplate")
+	}
+	return templates.RenderBytes(ctx, t.format.New(), doc.document(), opts.internal())
+}
+
+// Warnings reports the problems of the document, see Document.Warnings, followed by those that
+// are specific to the template, e.g., characters that LaTeX cannot typeset and replaces by "?".
+func (t Template) Warnings(doc *Document, opts Options) []string {
+	if t.format.New == nil {
+		return doc.Warnings(opts)
+	}
+	tmpl := t.format.New()
+	return doc.warnings(func(td *reader.TabularData) []string { return templates.TemplateWarnings(tmpl, td, opts.internal()) })
+}
+
+// TemplatesWith returns the names of the templates that support the option, e.g., OptionCustom.
+func TemplatesWith(option string) []string {
+	var res []string
+	for _, t := range Templates() {
+		if t.Supports(option) {
+			res = append(res, t.Name())
+		}
+	}
+	return res
+}
+
+// OptionNames returns the names of all options that templates can take into account.
+func OptionNames() []string {
+	var res []string
+	for _, o := range templates.KnownOptions() {
+		res = append(res, o.Name)
+	}
+	return res
+}